@@ -0,0 +1,139 @@
+// cmd/server/main.go
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/bass4/dcs-ice/internal/api"
+	"github.com/bass4/dcs-ice/internal/config"
+	"github.com/bass4/dcs-ice/internal/rules"
+)
+
+// Routes served by the DCS-ICE server
+const (
+	routeEvent     = "/api/dcs/event"
+	routeBatch     = "/api/dcs/batch"
+	routeWebSocket = "/api/dcs/ws"
+	routeReload    = "/api/rules/reload"
+	routeFacts     = "/facts"
+	routeFact      = "/facts/" // Followed by a fact ID
+	routeSchema    = "/api/actions/schema"
+	routeScheduled = "/api/actions/scheduled"
+	routeCancel    = "/api/actions/scheduled/" // Followed by a scheduled action ID
+	routeStatus    = "/api/actions/status"
+	routeRetry     = "/api/actions/retry/" // Followed by a failed action ID
+)
+
+// shutdownTimeout bounds how long draining connections may take
+const shutdownTimeout = 10 * time.Second
+
+// deliveryInterval is how often scheduled actions are checked for WebSocket delivery
+const deliveryInterval = time.Second
+
+func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "bench":
+			os.Exit(runBench(os.Args[2:]))
+		case "rules":
+			os.Exit(runRules(os.Args[2:]))
+		}
+	}
+
+	runServer()
+}
+
+// runServer starts the HTTP/WebSocket server and blocks until it is shut down
+func runServer() {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	if cfg.LogFile != "" {
+		logFile, err := os.OpenFile(cfg.LogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			log.Fatalf("Failed to open log file %s: %v", cfg.LogFile, err)
+		}
+		defer logFile.Close()
+		log.SetOutput(logFile)
+	}
+
+	if err := rules.SetGruleLogger(log.Writer(), cfg.LogLevel); err != nil {
+		log.Fatalf("Failed to configure rule engine logging: %v", err)
+	}
+
+	ruleEngine, err := rules.NewRuleEngine(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize rule engine: %v", err)
+	}
+
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	if cfg.WatchRules {
+		watcher := rules.NewRuleWatcher(ruleEngine, time.Duration(cfg.WatchDebounce)*time.Millisecond)
+		go watcher.Run(watchCtx)
+	}
+	go api.DeliverScheduledActions(watchCtx, ruleEngine, deliveryInterval)
+
+	server := &http.Server{
+		Addr:    net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		Handler: newRouter(ruleEngine),
+	}
+
+	serverErrors := make(chan error, 1)
+	go func() {
+		log.Printf("DCS-ICE listening on %s", server.Addr)
+		serverErrors <- server.ListenAndServe()
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-serverErrors:
+		log.Fatalf("Server failed: %v", err)
+	case sig := <-signals:
+		log.Printf("Received %s, shutting down", sig)
+	}
+
+	stopWatching()
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// WebSocket connections are hijacked and not tracked by http.Server, so drain them first
+	if err := api.DrainWebSockets(ctx); err != nil {
+		log.Printf("WebSocket drain incomplete: %v", err)
+	}
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("HTTP shutdown incomplete: %v", err)
+	}
+
+	log.Println("DCS-ICE stopped")
+}
+
+// newRouter mounts the DCS-ICE handlers on their documented routes
+func newRouter(ruleEngine *rules.RuleEngine) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc(routeEvent, api.DCSEventHandler(ruleEngine))
+	mux.HandleFunc(routeBatch, api.BatchDCSEventHandler(ruleEngine))
+	mux.HandleFunc(routeWebSocket, api.DCSWebSocketHandler(ruleEngine))
+	mux.HandleFunc(routeReload, api.ReloadRulesHandler(ruleEngine))
+	mux.HandleFunc(routeFacts, api.FactsHandler(ruleEngine))
+	mux.HandleFunc(routeFact, api.FactHandler(ruleEngine))
+	mux.HandleFunc(routeSchema, api.ActionSchemaHandler(ruleEngine))
+	mux.HandleFunc(routeScheduled, api.ScheduledActionsHandler(ruleEngine))
+	mux.HandleFunc(routeCancel, api.ScheduledActionHandler(ruleEngine))
+	mux.HandleFunc(routeStatus, api.ActionStatusHandler(ruleEngine))
+	mux.HandleFunc(routeRetry, api.ActionRetryHandler(ruleEngine))
+	return mux
+}
@@ -0,0 +1,156 @@
+// cmd/server/rules.go
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/bass4/dcs-ice/internal/api"
+	"github.com/bass4/dcs-ice/internal/config"
+	"github.com/bass4/dcs-ice/internal/rules"
+	"github.com/bass4/dcs-ice/internal/ruletest"
+)
+
+const rulesUsage = `Usage: dcs-ice rules <command> [config flags]
+
+Commands:
+  check   Compile the configured rule files and check them against the fact types
+  test    Evaluate the fixtures in -fixtures (default config/rule-tests) and compare the actions
+  schema  Print the action schemas as JSON, for the Lua executor
+`
+
+// runRules dispatches the "rules" subcommands and returns the process exit code
+func runRules(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, rulesUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "check":
+		return runRulesCheck(args[1:])
+	case "test":
+		return runRulesTest(args[1:])
+	case "schema":
+		return runRulesSchema(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown rules command %q\n\n%s", args[0], rulesUsage)
+		return 2
+	}
+}
+
+// runRulesCheck lints every rule file from the configured rule paths.
+// It exits non-zero if any problem is found so it can gate merges.
+func runRulesCheck(args []string) int {
+	cfg, err := config.LoadConfigFromArgs(args, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return 2
+	}
+
+	// Syntax errors are reported as diagnostics; grule's own logging would only repeat them
+	rules.SetGruleLogger(io.Discard, "error")
+
+	diagnostics, err := rules.CheckRules(cfg.RulesDirs, cfg.RulesFiles)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Rule check failed: %v\n", err)
+		return 2
+	}
+
+	for _, d := range diagnostics {
+		fmt.Println(d)
+	}
+
+	if len(diagnostics) > 0 {
+		fmt.Fprintf(os.Stderr, "%d problem(s) found\n", len(diagnostics))
+		return 1
+	}
+
+	fmt.Fprintln(os.Stderr, "No problems found")
+	return 0
+}
+
+// runRulesSchema prints the built-in and configured action schemas in the
+// format of the schema endpoint
+func runRulesSchema(args []string) int {
+	cfg, err := config.LoadConfigFromArgs(args, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return 2
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(api.ActionSchemaResponse{Schemas: rules.NewActionSchemaRegistry(cfg.ActionSchemas).Schemas()}); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to encode schemas: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// runRulesTest evaluates the rule fixtures and prints a pass/fail diff for each.
+// It exits non-zero if any fixture fails.
+func runRulesTest(args []string) int {
+	var fixturesDir *string
+	cfg, err := config.LoadConfigFromArgs(args, func(fs *flag.FlagSet) {
+		fixturesDir = fs.String("fixtures", "config/rule-tests", "Directory containing rule test fixtures (.json)")
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return 2
+	}
+
+	fixtures, err := ruletest.LoadFixtures(*fixturesDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 2
+	}
+
+	rules.SetGruleLogger(io.Discard, "error")
+	ruleEngine, err := rules.NewRuleEngineWithOutput(cfg, io.Discard)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize rule engine: %v\n", err)
+		return 2
+	}
+
+	passed, failed := 0, 0
+	for _, fixture := range fixtures {
+		result := ruletest.Run(ruleEngine, fixture)
+
+		if result.Passed {
+			passed++
+			fmt.Printf("PASS  %s (%s)\n", fixture.Name, fixture.File)
+			continue
+		}
+
+		failed++
+		fmt.Printf("FAIL  %s (%s)\n", fixture.Name, fixture.File)
+		if result.Err != nil {
+			fmt.Printf("      error: %v\n", result.Err)
+			continue
+		}
+		if result.Status != result.ExpectedStatus {
+			fmt.Printf("      status: got %s, want %s\n", result.Status, result.ExpectedStatus)
+		}
+		for _, action := range result.Missing {
+			fmt.Printf("      - %s\n", action)
+		}
+		for _, action := range result.Unexpected {
+			fmt.Printf("      + %s\n", action)
+		}
+		for _, rejected := range result.Rejected {
+			fmt.Printf("      rejected %s action from %s: %s\n", rejected.Action.ActionType, rejected.Rule, strings.Join(rejected.Problems, "; "))
+		}
+		fmt.Printf("      matched rules: %s\n", strings.Join(result.MatchedRules, ", "))
+	}
+
+	fmt.Printf("%d passed, %d failed\n", passed, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,261 @@
+// internal/api/actions.go
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/bass4/dcs-ice/internal/rules"
+	"github.com/bass4/dcs-ice/pkg/models"
+)
+
+// ActionSchemaResponse lists the schema of every action type the server sends
+type ActionSchemaResponse struct {
+	Schemas []models.ActionSchema `json:"schemas"`
+}
+
+// ActionSchemaHandler exports the action schemas, e.g. for the Lua executor
+func ActionSchemaHandler(ruleEngine *rules.RuleEngine) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, ActionSchemaResponse{Schemas: ruleEngine.ActionSchemas().Schemas()})
+	}
+}
+
+// DCSScheduledAction is an action a rule delayed to a later mission time
+type DCSScheduledAction struct {
+	ID     string    `json:"id"`
+	Rule   string    `json:"rule"`
+	DueAt  int64     `json:"due_at"` // Mission time in seconds
+	Action DCSAction `json:"action"`
+}
+
+// ScheduledActionsResponse lists the pending actions of a mission
+type ScheduledActionsResponse struct {
+	MissionID string               `json:"mission_id"`
+	Pending   []DCSScheduledAction `json:"pending"`
+}
+
+func convertScheduledAction(scheduled rules.ScheduledAction) DCSScheduledAction {
+	return DCSScheduledAction{
+		ID:     scheduled.ID,
+		Rule:   scheduled.Rule,
+		DueAt:  scheduled.DueAt,
+		Action: convertActionToDCSAction(scheduled.Action),
+	}
+}
+
+// ScheduledActionsHandler lists the pending actions of a mission (GET ?mission_id=)
+func ScheduledActionsHandler(ruleEngine *rules.RuleEngine) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		missionID := missionParam(r)
+		response := ScheduledActionsResponse{MissionID: missionID, Pending: make([]DCSScheduledAction, 0)}
+		for _, scheduled := range ruleEngine.ScheduledActions(missionID) {
+			response.Pending = append(response.Pending, convertScheduledAction(scheduled))
+		}
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+// ScheduledActionHandler cancels the pending action named by the last path
+// segment (DELETE /api/actions/scheduled/{id})
+func ScheduledActionHandler(ruleEngine *rules.RuleEngine) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			w.Header().Set("Allow", "DELETE")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		missionID := missionParam(r)
+		scheduled, ok := ruleEngine.CancelScheduledAction(missionID, id)
+		if !ok {
+			http.Error(w, fmt.Sprintf("No pending action %q in mission %q", id, missionID), http.StatusNotFound)
+			return
+		}
+
+		fmt.Fprintf(ruleEngine.Output(), "Cancelled scheduled %s action %s from rule %s\n", scheduled.Action.Type, scheduled.ID, scheduled.Rule)
+		writeJSON(w, http.StatusOK, convertScheduledAction(scheduled))
+	}
+}
+
+// DeliverScheduledActions pushes scheduled actions to WebSocket clients once
+// they are due, checking every interval until ctx is done. Each mission's
+// actions go to the client that most recently sent an event for it; missions
+// without one get their actions with their next HTTP request instead. Actions
+// that cannot be pushed go back in the queue. Each check first completes the
+// absence patterns whose window passed, whose actions are delivered the same way.
+func DeliverScheduledActions(ctx context.Context, ruleEngine *rules.RuleEngine, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ruleEngine.CompleteAbsencePatterns()
+
+		for missionID, client := range connections.missionClients() {
+			due := ruleEngine.DueActions(missionID)
+			if len(due) == 0 {
+				continue
+			}
+
+			actions := make([]models.Action, 0, len(due))
+			for _, scheduled := range due {
+				actions = append(actions, scheduled.Action)
+			}
+			responseJSON, err := json.Marshal(convertActionsToDCSResponse(actions))
+			if err != nil {
+				log.Printf("Failed to encode scheduled actions: %v", err)
+				ruleEngine.RequeueScheduledActions(missionID, due)
+				continue
+			}
+			if err := client.write(websocket.TextMessage, responseJSON); err != nil {
+				log.Printf("Failed to push %d scheduled actions for mission %s: %v", len(due), missionID, err)
+				ruleEngine.RequeueScheduledActions(missionID, due)
+				continue
+			}
+			log.Printf("Pushed %d scheduled actions for mission %s", len(due), missionID)
+		}
+	}
+}
+
+// AckEventType is the event DCS sends to report on an action it received:
+//
+//	{"event_type": "action_ack", "mission_id": "...", "data": {"action_id": "act-12", "status": "failed", "reason": "zone not found"}}
+//
+// status is executed or failed; group_name optionally names the group DCS created.
+const AckEventType = "action_ack"
+
+// ProcessAck records an action_ack event in the action log and evaluates the
+// single-event rules against an action_executed or action_failed message
+func ProcessAck(ruleEngine *rules.RuleEngine, dcsEvent DCSEvent, includeTrace bool) DCSResponse {
+	message, err := convertAckToMessage(ruleEngine, dcsEvent)
+	if err != nil {
+		return buildDCSResponse(nil, err, includeTrace)
+	}
+	result, err := ruleEngine.EvaluateMessage(message)
+	return buildDCSResponse(result, err, includeTrace)
+}
+
+// convertAckToMessage updates the acknowledged action's status and describes
+// the outcome as a message carrying the action's zone, unit type, group and
+// count. The payload holds action_id, action_type, sub_type, rule, status,
+// reason, attempt and retry_of.
+func convertAckToMessage(ruleEngine *rules.RuleEngine, dcsEvent DCSEvent) (*models.Message, error) {
+	getString := func(key string) string {
+		value, _ := dcsEvent.Data[key].(string)
+		return value
+	}
+
+	id := getString("action_id")
+	if id == "" {
+		return nil, &EventValidationError{Field: "action_id", Message: "required for action_ack events"}
+	}
+	status := models.ActionStatus(getString("status"))
+	if status != models.ActionExecuted && status != models.ActionFailed {
+		return nil, &EventValidationError{Field: "status", Message: fmt.Sprintf("expected %q or %q, got %q", models.ActionExecuted, models.ActionFailed, status)}
+	}
+
+	missionID := dcsEvent.MissionID
+	if missionID == "" {
+		missionID = models.DefaultMissionID
+	}
+	record, err := ruleEngine.AcknowledgeAction(missionID, id, status, getString("reason"), getString("group_name"))
+	if err != nil {
+		return nil, &EventValidationError{Field: "action_id", Message: err.Error()}
+	}
+
+	message := models.NewMessage("action_" + string(status))
+	message.MissionID = missionID
+	message.Timestamp = dcsEvent.Timestamp
+	message.Zone = record.Action.Zone
+	message.UnitType = record.Action.UnitType
+	message.GroupName = record.GroupName
+	if message.GroupName == "" {
+		message.GroupName = record.Action.GroupName
+	}
+	message.Count, _ = strconv.Atoi(record.Action.Count)
+	message.Data = map[string]interface{}{
+		"action_id":   record.ID,
+		"action_type": record.Action.Type,
+		"sub_type":    record.Action.SubType,
+		"rule":        record.Action.Rule,
+		"status":      string(record.Status),
+		"reason":      record.Reason,
+		"attempt":     float64(record.Attempt),
+		"retry_of":    record.Action.RetryOf,
+	}
+	return message, nil
+}
+
+// ActionStatusResponse lists the actions handed out for a mission
+type ActionStatusResponse struct {
+	MissionID string                `json:"mission_id"`
+	Actions   []models.ActionRecord `json:"actions"`
+}
+
+// ActionStatusHandler lists the actions of a mission with their status
+// (GET ?mission_id=&status=), e.g. status=failed for the ones DCS could not execute
+func ActionStatusHandler(ruleEngine *rules.RuleEngine) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		missionID := missionParam(r)
+		status := models.ActionStatus(r.URL.Query().Get("status"))
+		writeJSON(w, http.StatusOK, ActionStatusResponse{
+			MissionID: missionID,
+			Actions:   ruleEngine.ActionLog(missionID).List(status),
+		})
+	}
+}
+
+// ActionRetryHandler sends the failed action named by the last path segment
+// again (POST /api/actions/retry/{id}). The retry is delivered like a
+// scheduled action that is due at once.
+func ActionRetryHandler(ruleEngine *rules.RuleEngine) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		missionID := missionParam(r)
+		scheduled, err := ruleEngine.RetryAction(missionID, id)
+		switch {
+		case errors.Is(err, rules.ErrUnknownAction):
+			http.Error(w, fmt.Sprintf("No action %q in mission %q", id, missionID), http.StatusNotFound)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+
+		writeJSON(w, http.StatusAccepted, convertScheduledAction(scheduled))
+	}
+}
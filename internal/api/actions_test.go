@@ -0,0 +1,84 @@
+// internal/api/actions_test.go
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bass4/dcs-ice/pkg/models"
+)
+
+const delayedRules = `
+rule DelayedReinforcement "Reinforce BRAVO after ten minutes" {
+    when
+        Message.Event == "unit_destroyed"
+    then
+        Actions.AddReinforceAction("SAM_BRAVO", "BRAVO", "SA-6", 2);
+        Actions.DelayLast(600);
+        Retract("DelayedReinforcement");
+}
+`
+
+func TestScheduledActionHandlerCancels(t *testing.T) {
+	var out bytes.Buffer
+	ruleEngine := newTestEngine(t, delayedRules, &out)
+
+	message := models.NewMessage("unit_destroyed")
+	message.MissionID = "op-anvil"
+	result, err := ruleEngine.EvaluateMessage(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Scheduled) != 1 {
+		t.Fatalf("got %d scheduled actions, want 1", len(result.Scheduled))
+	}
+	id := result.Scheduled[0].ID
+
+	cancel := func() int {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodDelete, "/api/actions/scheduled/"+id+"?mission_id=op-anvil", nil)
+		ScheduledActionHandler(ruleEngine)(recorder, request)
+		return recorder.Code
+	}
+	if code := cancel(); code != http.StatusOK {
+		t.Fatalf("cancel: HTTP %d", code)
+	}
+	if code := cancel(); code != http.StatusNotFound {
+		t.Errorf("second cancel: HTTP %d, want 404", code)
+	}
+
+	if record, _ := ruleEngine.ActionLog("op-anvil").Get(id); record.Status != models.ActionCancelled {
+		t.Errorf("cancelled action is %s", record.Status)
+	}
+	if !strings.Contains(out.String(), "Cancelled scheduled reinforce action "+id) {
+		t.Errorf("engine output lacks the cancellation:\n%s", out.String())
+	}
+}
+
+func TestActionSchemaHandler(t *testing.T) {
+	ruleEngine := newTestEngine(t, delayedRules, io.Discard)
+
+	recorder := httptest.NewRecorder()
+	ActionSchemaHandler(ruleEngine)(recorder, httptest.NewRequest(http.MethodGet, "/api/actions/schema", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("HTTP %d, want 200", recorder.Code)
+	}
+
+	var response ActionSchemaResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Schemas) != len(models.DefaultActionSchemas()) {
+		t.Fatalf("got %d schemas, want the %d defaults", len(response.Schemas), len(models.DefaultActionSchemas()))
+	}
+	for i := 1; i < len(response.Schemas); i++ {
+		if response.Schemas[i-1].Type >= response.Schemas[i].Type {
+			t.Errorf("schemas are not sorted by type: %s before %s", response.Schemas[i-1].Type, response.Schemas[i].Type)
+		}
+	}
+}
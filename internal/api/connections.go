@@ -0,0 +1,141 @@
+// internal/api/connections.go
+package api
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/bass4/dcs-ice/pkg/models"
+)
+
+// wsRegistry keeps track of open WebSocket connections so they can be
+// drained on shutdown and receive scheduled actions
+type wsRegistry struct {
+	mu      sync.Mutex
+	conns   map[*websocket.Conn]*wsClient
+	wg      sync.WaitGroup
+	closing bool
+}
+
+// wsClient is an open WebSocket connection. Responses and pushed actions are
+// written from different goroutines, so writes are serialized.
+type wsClient struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+
+	mu         sync.Mutex
+	missionID  string    // Mission of the latest event received
+	lastActive time.Time // When that event was received
+}
+
+// connections is the registry shared by all WebSocket handlers
+var connections = &wsRegistry{
+	conns: make(map[*websocket.Conn]*wsClient),
+}
+
+// add registers a connection. It returns false if the server is draining.
+func (r *wsRegistry) add(conn *websocket.Conn) (*wsClient, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closing {
+		return nil, false
+	}
+	client := &wsClient{conn: conn}
+	r.conns[conn] = client
+	r.wg.Add(1)
+	return client, true
+}
+
+// write sends a message on the connection
+func (c *wsClient) write(messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.conn.WriteMessage(messageType, data)
+}
+
+// seen records that the client sent an event for a mission
+func (c *wsClient) seen(missionID string) {
+	if missionID == "" {
+		missionID = models.DefaultMissionID
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.missionID = missionID
+	c.lastActive = time.Now()
+}
+
+// missionClients returns, for every mission, the client that most recently
+// sent an event for it
+func (r *wsRegistry) missionClients() map[string]*wsClient {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	clients := make(map[string]*wsClient)
+	active := make(map[string]time.Time)
+	for _, client := range r.conns {
+		client.mu.Lock()
+		missionID, lastActive := client.missionID, client.lastActive
+		client.mu.Unlock()
+		if missionID != "" && lastActive.After(active[missionID]) {
+			clients[missionID] = client
+			active[missionID] = lastActive
+		}
+	}
+	return clients
+}
+
+// remove unregisters a connection once its handler has finished
+func (r *wsRegistry) remove(conn *websocket.Conn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.conns[conn]; ok {
+		delete(r.conns, conn)
+		r.wg.Done()
+	}
+}
+
+// DrainWebSockets stops accepting new WebSocket connections, asks every open
+// connection to close and waits for their handlers to finish. Connections still
+// open when ctx expires are closed forcibly.
+func DrainWebSockets(ctx context.Context) error {
+	connections.mu.Lock()
+	connections.closing = true
+	open := make([]*websocket.Conn, 0, len(connections.conns))
+	for conn := range connections.conns {
+		open = append(open, conn)
+	}
+	connections.mu.Unlock()
+
+	log.Printf("Draining %d WebSocket connection(s)", len(open))
+
+	closeMessage := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	for _, conn := range open {
+		if err := conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(time.Second)); err != nil {
+			log.Printf("Failed to send close frame: %v", err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		connections.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		connections.mu.Lock()
+		for conn := range connections.conns {
+			conn.Close()
+		}
+		connections.mu.Unlock()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,161 @@
+// internal/api/facts.go
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bass4/dcs-ice/internal/rules"
+	"github.com/bass4/dcs-ice/pkg/models"
+)
+
+// FactsRequest asserts facts into a mission's working memory
+type FactsRequest struct {
+	MissionID string        `json:"mission_id,omitempty"` // Defaults to "default"
+	Facts     []models.Fact `json:"facts"`
+}
+
+// FactsResponse is the evaluation result of asserting facts, together with the stored facts
+type FactsResponse struct {
+	DCSResponse
+	Facts []models.Fact `json:"facts"`
+}
+
+// FactListResponse lists the facts of a mission
+type FactListResponse struct {
+	MissionID string        `json:"mission_id"`
+	Facts     []models.Fact `json:"facts"`
+}
+
+// FactsHandler lists the facts of a mission (GET) or asserts facts and
+// evaluates the rules against them (POST). Asserting a fact with the ID of an
+// existing one replaces it.
+func FactsHandler(ruleEngine *rules.RuleEngine) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			missionID := missionParam(r)
+			writeJSON(w, http.StatusOK, FactListResponse{
+				MissionID: missionID,
+				Facts:     ruleEngine.Facts(missionID).List(),
+			})
+
+		case http.MethodPost:
+			var request FactsRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			fmt.Fprintf(ruleEngine.Output(), "Received %d facts\n", len(request.Facts))
+
+			response := AssertFacts(ruleEngine, request, wantsTrace(r))
+			status := http.StatusOK
+			if response.Status == StatusError {
+				status = http.StatusBadRequest
+				if response.Error != nil && response.Error.Kind != "validation" {
+					status = http.StatusInternalServerError
+				}
+			}
+			writeJSON(w, status, response)
+
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// FactHandler retracts the fact named by the last path segment (DELETE /facts/{id})
+func FactHandler(ruleEngine *rules.RuleEngine) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			w.Header().Set("Allow", "DELETE")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		missionID := missionParam(r)
+		fact, ok := ruleEngine.Facts(missionID).Retract(id)
+		if !ok {
+			http.Error(w, fmt.Sprintf("No fact %q in mission %q", id, missionID), http.StatusNotFound)
+			return
+		}
+
+		fmt.Fprintf(ruleEngine.Output(), "Retracted fact %s: Type=%s\n", fact.ID, fact.Type)
+		writeJSON(w, http.StatusOK, fact)
+	}
+}
+
+// isCount reports whether a fact count is a non-negative integer
+func isCount(count string) bool {
+	n, err := strconv.Atoi(count)
+	return err == nil && n >= 0
+}
+
+// AssertFacts validates and stores facts in their mission's working memory,
+// then evaluates the single-event rules. Nothing is stored if any fact is invalid.
+func AssertFacts(ruleEngine *rules.RuleEngine, request FactsRequest, includeTrace bool) FactsResponse {
+	missionID := request.MissionID
+	if missionID == "" {
+		missionID = models.DefaultMissionID
+	}
+
+	for i, fact := range request.Facts {
+		var err error
+		switch {
+		case fact.Type == "":
+			err = &EventValidationError{Field: "type", Message: "required"}
+		case fact.Count != "" && !isCount(fact.Count):
+			err = &EventValidationError{Field: "count", Message: fmt.Sprintf("must be a non-negative integer, got %q", fact.Count)}
+		case fact.MissionID != "" && fact.MissionID != missionID:
+			err = &EventValidationError{Field: "mission_id", Message: fmt.Sprintf("%q differs from %q of the request", fact.MissionID, missionID)}
+		}
+		if err != nil {
+			return FactsResponse{
+				DCSResponse: buildDCSResponse(nil, fmt.Errorf("fact %d: %w", i, err), includeTrace),
+				Facts:       make([]models.Fact, 0),
+			}
+		}
+	}
+
+	store := ruleEngine.Facts(missionID)
+	stored := make([]models.Fact, 0, len(request.Facts))
+	for _, fact := range request.Facts {
+		fact.MissionID = missionID
+		fact, created := store.Assert(fact)
+		if created {
+			fmt.Fprintf(ruleEngine.Output(), "Asserted fact %s: Type=%s, Value=%s\n", fact.ID, fact.Type, fact.Value)
+		} else {
+			fmt.Fprintf(ruleEngine.Output(), "Updated fact %s: Type=%s, Value=%s\n", fact.ID, fact.Type, fact.Value)
+		}
+		stored = append(stored, fact)
+	}
+
+	result, err := ruleEngine.EvaluateFacts(missionID)
+	return FactsResponse{
+		DCSResponse: buildDCSResponse(result, err, includeTrace),
+		Facts:       stored,
+	}
+}
+
+// missionParam returns the ?mission_id= query parameter, defaulting to "default"
+func missionParam(r *http.Request) string {
+	if missionID := r.URL.Query().Get("mission_id"); missionID != "" {
+		return missionID
+	}
+	return models.DefaultMissionID
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to encode response: %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+// internal/api/facts_test.go
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const factRules = `
+rule BravoSuppressed "SAMs in BRAVO are suppressed" {
+    when
+        Facts.HasTypeInZone("sam_suppressed", "BRAVO")
+    then
+        Actions.AddAlertAction("sead", "yellow", "BRAVO suppressed");
+        Retract("BravoSuppressed");
+}
+`
+
+func TestFactsHandlerReportsOnEngineOutput(t *testing.T) {
+	var out bytes.Buffer
+	ruleEngine := newTestEngine(t, factRules, &out)
+
+	body := `{"mission_id": "op-anvil", "facts": [{"id": "bravo-sead", "type": "sam_suppressed", "zone": "BRAVO", "count": "2"}]}`
+	recorder := httptest.NewRecorder()
+	FactsHandler(ruleEngine)(recorder, httptest.NewRequest(http.MethodPost, "/facts", strings.NewReader(body)))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("HTTP %d: %s", recorder.Code, recorder.Body)
+	}
+	if !strings.Contains(recorder.Body.String(), "BRAVO suppressed") {
+		t.Errorf("response lacks the alert: %s", recorder.Body)
+	}
+
+	recorder = httptest.NewRecorder()
+	FactHandler(ruleEngine)(recorder, httptest.NewRequest(http.MethodDelete, "/facts/bravo-sead?mission_id=op-anvil", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("HTTP %d: %s", recorder.Code, recorder.Body)
+	}
+
+	for _, line := range []string{"Received 1 facts", "Asserted fact bravo-sead", "Retracted fact bravo-sead"} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("engine output lacks %q:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestAssertFactsValidation(t *testing.T) {
+	ruleEngine := newTestEngine(t, factRules, &bytes.Buffer{})
+
+	tests := []struct {
+		body string
+		want string // Error message, or "" for success
+	}{
+		{`{"facts": [{"type": "sam_suppressed", "zone": "BRAVO"}]}`, ""},
+		{`{"facts": [{"zone": "BRAVO"}]}`, "fact 0: invalid type: required"},
+		{`{"facts": [{"type": "t"}, {"type": "t", "count": "-1"}]}`, `fact 1: invalid count: must be a non-negative integer, got "-1"`},
+		{`{"mission_id": "a", "facts": [{"type": "t", "mission_id": "b"}]}`, `fact 0: invalid mission_id: "b" differs from "a" of the request`},
+	}
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		FactsHandler(ruleEngine)(recorder, httptest.NewRequest(http.MethodPost, "/facts", strings.NewReader(tt.body)))
+		if tt.want == "" {
+			if recorder.Code != http.StatusOK {
+				t.Errorf("%s: HTTP %d: %s", tt.body, recorder.Code, recorder.Body)
+			}
+			continue
+		}
+		var response FactsResponse
+		if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if recorder.Code != http.StatusBadRequest || response.Error == nil || response.Error.Message != tt.want {
+			t.Errorf("%s: HTTP %d %+v, want 400 with %q", tt.body, recorder.Code, response.Error, tt.want)
+		}
+	}
+}
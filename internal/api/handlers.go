@@ -2,288 +2,611 @@
 package api
 
 import (
-    "encoding/json"
-    "fmt"
-    "log"
-    "net/http"
-
-    "github.com/gorilla/websocket"
-
-    "github.com/bass4/dcs-ice/internal/rules"
-    "github.com/bass4/dcs-ice/pkg/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/bass4/dcs-ice/internal/rules"
+	"github.com/bass4/dcs-ice/pkg/models"
 )
 
 // DCSEvent represents the JSON structure coming from DCS
 type DCSEvent struct {
-    EventType string                 `json:"event_type"`
-    Timestamp int64                  `json:"timestamp"`
-    Data      map[string]interface{} `json:"data"`
+	MissionID string                 `json:"mission_id,omitempty"` // Selects the mission's world state; defaults to "default"
+	EventType string                 `json:"event_type"`
+	Timestamp int64                  `json:"timestamp"`
+	Data      map[string]interface{} `json:"data"`
 }
 
 // DCSAction represents an action to be sent back to DCS
 type DCSAction struct {
-    ActionType string                 `json:"action_type"`
-    SubType    string                 `json:"sub_type,omitempty"`
-    Data       map[string]interface{} `json:"data"`
+	ID         string                 `json:"id,omitempty"` // Echoed by DCS in action_ack events
+	ActionType string                 `json:"action_type"`
+	SubType    string                 `json:"sub_type,omitempty"`
+	Data       map[string]interface{} `json:"data"`
 }
 
 // DCSResponse represents the complete response to DCS
 type DCSResponse struct {
-    Status  string      `json:"status"`
-    Actions []DCSAction `json:"actions"`
+	Status       string               `json:"status"`
+	Actions      []DCSAction          `json:"actions"`
+	MatchedRules []string             `json:"matchedRules"`
+	Rejected     []DCSRejectedAction  `json:"rejected,omitempty"`  // Actions dropped for not matching their schema
+	Scheduled    []DCSScheduledAction `json:"scheduled,omitempty"` // Actions delayed by the rules, delivered once due
+	Trace        []DCSTraceEntry      `json:"trace,omitempty"`     // Only present when requested with ?trace=true
+	Error        *DCSError            `json:"error,omitempty"`
+}
+
+// Response statuses
+const (
+	StatusSuccess = "success"
+	StatusPartial = "partial" // Evaluation stopped early; actions collected until then are included
+	StatusError   = "error"   // Nothing was evaluated
+)
+
+// DCSError details why an evaluation did not complete
+type DCSError struct {
+	Kind    string `json:"kind"` // validation, cycle_exhausted, rule_condition, rule_runtime, data_context or internal
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+// DCSRejectedAction is an action that was not sent because it does not match its schema
+type DCSRejectedAction struct {
+	Rule     string    `json:"rule"`
+	Action   DCSAction `json:"action"`
+	Problems []string  `json:"problems"`
+}
+
+// DCSTraceEntry describes one rule firing during an evaluation
+type DCSTraceEntry struct {
+	Rule    string      `json:"rule"`
+	Cycle   uint64      `json:"cycle"`
+	Actions []DCSAction `json:"actions"`
+	Derived string      `json:"derived,omitempty"` // Set when the rule fired for a message derived from an event pattern
+
+	// Actions held back by a cooldown, a budget or deduplication
+	Suppressed []DCSSuppressedAction `json:"suppressed,omitempty"`
+
+	// Actions delayed to a later mission time
+	Scheduled []DCSScheduledAction `json:"scheduled,omitempty"`
+}
+
+// DCSSuppressedAction is an action that was not sent because of an action limit
+type DCSSuppressedAction struct {
+	Action DCSAction `json:"action"`
+	Reason string    `json:"reason"`
 }
 
 // DCSEventHandler handles incoming DCS events via HTTP
 func DCSEventHandler(ruleEngine *rules.RuleEngine) http.HandlerFunc {
-    return func(w http.ResponseWriter, r *http.Request) {
-        // Parse the incoming JSON
-        var dcsEvent DCSEvent
-        if err := json.NewDecoder(r.Body).Decode(&dcsEvent); err != nil {
-            http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
-            return
-        }
-
-        fmt.Printf("Received event: %s\n", dcsEvent.EventType)
-
-        // Convert DCS event to Message
-        message := convertDCSEventToMessage(dcsEvent)
-
-        // Process the message through the rules engine
-        actions, err := ruleEngine.ProcessMessage(message)
-        if err != nil {
-            http.Error(w, "Rule processing failed: "+err.Error(), http.StatusInternalServerError)
-            return
-        }
-
-        // Convert actions to DCS response format
-        dcsResponse := convertActionsToDCSResponse(actions)
-
-        // Send response back to DCS
-        w.Header().Set("Content-Type", "application/json")
-        if err := json.NewEncoder(w).Encode(dcsResponse); err != nil {
-            http.Error(w, "Failed to encode response: "+err.Error(), http.StatusInternalServerError)
-            return
-        }
-        
-        fmt.Println("Response sent successfully")
-    }
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse the incoming JSON
+		var dcsEvent DCSEvent
+		if err := json.NewDecoder(r.Body).Decode(&dcsEvent); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		fmt.Printf("Received event: %s\n", dcsEvent.EventType)
+
+		// Process the event through the rules engine
+		dcsResponse := ProcessEvent(ruleEngine, dcsEvent, wantsTrace(r))
+
+		// Send response back to DCS
+		if err := writeDCSResponse(w, dcsResponse); err != nil {
+			log.Printf("Failed to encode response: %v", err)
+			return
+		}
+
+		fmt.Println("Response sent successfully")
+	}
 }
 
 // WebSocket support
 var upgrader = websocket.Upgrader{
-    ReadBufferSize:  1024,
-    WriteBufferSize: 1024,
-    CheckOrigin: func(r *http.Request) bool {
-        return true
-    },
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
 }
 
 // DCSWebSocketHandler handles WebSocket connections from DCS
 func DCSWebSocketHandler(ruleEngine *rules.RuleEngine) http.HandlerFunc {
-    return func(w http.ResponseWriter, r *http.Request) {
-        conn, err := upgrader.Upgrade(w, r, nil)
-        if err != nil {
-            log.Println("WebSocket upgrade failed:", err)
-            return
-        }
-        defer conn.Close()
-
-        log.Println("WebSocket connection established")
-
-        // WebSocket message handling loop
-        for {
-            messageType, messageData, err := conn.ReadMessage()
-            if err != nil {
-                if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-                    log.Printf("WebSocket error: %v", err)
-                }
-                break
-            }
-
-            // Parse DCS event
-            var dcsEvent DCSEvent
-            if err := json.Unmarshal(messageData, &dcsEvent); err != nil {
-                log.Printf("Invalid JSON: %v", err)
-                continue
-            }
-
-            log.Printf("Received event: %s", dcsEvent.EventType)
-
-            // Convert and process
-            message := convertDCSEventToMessage(dcsEvent)
-            actions, err := ruleEngine.ProcessMessage(message)
-            if err != nil {
-                log.Printf("Rule processing failed: %v", err)
-                continue
-            }
-
-            // Convert actions to response
-            dcsResponse := convertActionsToDCSResponse(actions)
-            responseJSON, err := json.Marshal(dcsResponse)
-            if err != nil {
-                log.Printf("Failed to encode response: %v", err)
-                continue
-            }
-
-            // Send response back to DCS
-            if err := conn.WriteMessage(messageType, responseJSON); err != nil {
-                log.Printf("Failed to send response: %v", err)
-                break
-            }
-
-            log.Printf("Sent %d actions back to DCS", len(dcsResponse.Actions))
-        }
-    }
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			log.Println("WebSocket upgrade failed:", err)
+			return
+		}
+		defer conn.Close()
+
+		client, ok := connections.add(conn)
+		if !ok {
+			conn.WriteMessage(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"))
+			return
+		}
+		defer connections.remove(conn)
+
+		includeTrace := wantsTrace(r)
+
+		log.Println("WebSocket connection established")
+
+		// WebSocket message handling loop
+		for {
+			messageType, messageData, err := conn.ReadMessage()
+			if err != nil {
+				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+					log.Printf("WebSocket error: %v", err)
+				}
+				break
+			}
+
+			// Parse DCS event
+			var dcsEvent DCSEvent
+			if err := json.Unmarshal(messageData, &dcsEvent); err != nil {
+				log.Printf("Invalid JSON: %v", err)
+				continue
+			}
+
+			log.Printf("Received event: %s", dcsEvent.EventType)
+			client.seen(dcsEvent.MissionID)
+
+			// Convert and process
+			dcsResponse := ProcessEvent(ruleEngine, dcsEvent, includeTrace)
+			responseJSON, err := json.Marshal(dcsResponse)
+			if err != nil {
+				log.Printf("Failed to encode response: %v", err)
+				continue
+			}
+
+			// Send response back to DCS
+			if err := client.write(messageType, responseJSON); err != nil {
+				log.Printf("Failed to send response: %v", err)
+				break
+			}
+
+			log.Printf("Sent %d actions back to DCS", len(dcsResponse.Actions))
+		}
+	}
 }
 
 // internal/api/handlers.go
 
-// ReloadRulesHandler provides an endpoint to reload rules
+// ReloadRulesResponse is returned by the reload endpoint
+type ReloadRulesResponse struct {
+	Status  string                `json:"status"`
+	Message string                `json:"message"`
+	Version uint64                `json:"version"`
+	Errors  []rules.RuleFileError `json:"errors,omitempty"`
+}
+
+// ReloadRulesHandler provides an endpoint to reload rules.
+// On failure the previous rules keep serving and the response lists the compile errors per file.
 func ReloadRulesHandler(ruleEngine *rules.RuleEngine) http.HandlerFunc {
-    return func(w http.ResponseWriter, r *http.Request) {
-        if err := ruleEngine.ReloadRules(); err != nil {
-            http.Error(w, "Failed to reload rules: "+err.Error(), http.StatusInternalServerError)
-            return
-        }
-        
-        w.Header().Set("Content-Type", "application/json")
-        json.NewEncoder(w).Encode(map[string]string{"status": "success", "message": "Rules reloaded successfully"})
-    }
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := ruleEngine.ReloadRules(); err != nil {
+			response := ReloadRulesResponse{
+				Status:  "error",
+				Message: "Failed to reload rules: " + err.Error(),
+				Version: ruleEngine.Version(),
+			}
+			var reloadErr *rules.ReloadError
+			if errors.As(err, &reloadErr) {
+				response.Errors = reloadErr.Files
+			}
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+
+		json.NewEncoder(w).Encode(ReloadRulesResponse{
+			Status:  "success",
+			Message: "Rules reloaded successfully",
+			Version: ruleEngine.Version(),
+		})
+	}
 }
 
 // Helper functions for data conversion
 
-// convertDCSEventToMessage converts a DCS event to a Message
-func convertDCSEventToMessage(dcsEvent DCSEvent) *models.Message {
-    message := models.NewMessage(dcsEvent.EventType)
-    
-    // Extract common fields
-    getString := func(data map[string]interface{}, key string) string {
-        if val, ok := data[key]; ok {
-            if strVal, ok := val.(string); ok {
-                return strVal
-            }
-        }
-        return ""
-    }
-    
-    // Set fields based on the event data
-    message.Zone = getString(dcsEvent.Data, "zone")
-    message.UnitType = getString(dcsEvent.Data, "unit_type")
-    message.UnitName = getString(dcsEvent.Data, "unit_name")
-    message.GroupName = getString(dcsEvent.Data, "group_name")
-    
-    // Handle specific event types
-    switch dcsEvent.EventType {
-    case "alert_level_change":
-        message.Level = getString(dcsEvent.Data, "level")
-    case "unit_detected":
-        message.Count = getString(dcsEvent.Data, "count")
-        if message.Count == "" {
-            message.Count = "1" // Default to 1 if not specified
-        }
-    }
-    
-    fmt.Printf("Created message: Event=%s, Zone=%s, UnitType=%s\n", 
-        message.Event, message.Zone, message.UnitType)
-    
-    return message
+// EventValidationError is returned for an event whose data cannot be converted to a message
+type EventValidationError struct {
+	Field   string
+	Message string
 }
 
+func (e *EventValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Message)
+}
+
+// convertDCSEventToMessage converts a DCS event to a Message.
+// Numbers may be sent as JSON numbers or numeric strings; malformed values
+// are reported as an *EventValidationError.
+func convertDCSEventToMessage(dcsEvent DCSEvent) (*models.Message, error) {
+	message := models.NewMessage(dcsEvent.EventType)
+	message.MissionID = dcsEvent.MissionID
+	if message.MissionID == "" {
+		message.MissionID = models.DefaultMissionID
+	}
+	message.Timestamp = dcsEvent.Timestamp
+	message.Data = dcsEvent.Data
+
+	// Extract common fields
+	getString := func(data map[string]interface{}, key string) string {
+		if val, ok := data[key]; ok {
+			if strVal, ok := val.(string); ok {
+				return strVal
+			}
+		}
+		return ""
+	}
+
+	// Set fields based on the event data
+	message.Zone = getString(dcsEvent.Data, "zone")
+	message.UnitType = getString(dcsEvent.Data, "unit_type")
+	message.UnitName = getString(dcsEvent.Data, "unit_name")
+	message.GroupName = getString(dcsEvent.Data, "group_name")
+
+	if level, ok := dcsEvent.Data["level"]; ok {
+		levelStr, isString := level.(string)
+		if !isString {
+			return nil, &EventValidationError{Field: "level", Message: fmt.Sprintf("expected a string, got %v", level)}
+		}
+		parsed, err := models.ParseAlertLevel(levelStr)
+		if err != nil {
+			return nil, &EventValidationError{Field: "level", Message: err.Error()}
+		}
+		message.Level = parsed
+	}
+
+	if coalition, ok := dcsEvent.Data["coalition"]; ok && coalition != nil {
+		var coalitionStr string
+		switch v := coalition.(type) {
+		case string:
+			coalitionStr = v
+		case float64:
+			coalitionStr = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return nil, &EventValidationError{Field: "coalition", Message: fmt.Sprintf("expected a string, got %v", coalition)}
+		}
+		parsed, err := models.ParseCoalition(coalitionStr)
+		if err != nil {
+			return nil, &EventValidationError{Field: "coalition", Message: err.Error()}
+		}
+		message.Coalition = parsed
+	}
+
+	count, hasCount, err := getCount(dcsEvent.Data, "count")
+	if err != nil {
+		return nil, err
+	}
+	message.Count = count
+
+	if position, ok := dcsEvent.Data["position"]; ok && position != nil {
+		parsed, err := getPosition(position)
+		if err != nil {
+			return nil, err
+		}
+		message.Position = parsed
+		message.HasPosition = true
+	}
+
+	// Handle specific event types
+	switch dcsEvent.EventType {
+	case "alert_level_change":
+		if message.Level == "" {
+			return nil, &EventValidationError{Field: "level", Message: "required for alert_level_change events"}
+		}
+	case "unit_detected":
+		if !hasCount {
+			message.Count = 1 // Default to 1 if not specified
+		}
+	}
+
+	return message, nil
+}
+
+// logMessage reports a converted message where the engine reports its evaluations
+func logMessage(ruleEngine *rules.RuleEngine, message *models.Message) {
+	fmt.Fprintf(ruleEngine.Output(), "Created message: Event=%s, Zone=%s, UnitType=%s\n",
+		message.Event, message.Zone, message.UnitType)
+}
+
+// getPosition reads an {x, y, z} object; each coordinate may be a JSON number or a numeric string
+func getPosition(val interface{}) (models.Position, error) {
+	obj, ok := val.(map[string]interface{})
+	if !ok {
+		return models.Position{}, &EventValidationError{Field: "position", Message: fmt.Sprintf("expected an object with x, y and z, got %v", val)}
+	}
+
+	var coordinates [3]float64
+	for i, axis := range []string{"x", "y", "z"} {
+		switch v := obj[axis].(type) {
+		case float64:
+			coordinates[i] = v
+		case string:
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return models.Position{}, &EventValidationError{Field: "position." + axis, Message: fmt.Sprintf("%q is not a number", v)}
+			}
+			coordinates[i] = f
+		case nil:
+			return models.Position{}, &EventValidationError{Field: "position." + axis, Message: "missing"}
+		default:
+			return models.Position{}, &EventValidationError{Field: "position." + axis, Message: fmt.Sprintf("expected a number, got %v", v)}
+		}
+	}
+
+	return models.Position{X: coordinates[0], Y: coordinates[1], Z: coordinates[2]}, nil
+}
+
+// getCount reads a non-negative integer sent as a JSON number or a numeric string
+func getCount(data map[string]interface{}, key string) (int, bool, error) {
+	val, ok := data[key]
+	if !ok || val == nil {
+		return 0, false, nil
+	}
+
+	var count int
+	switch v := val.(type) {
+	case float64:
+		if v != math.Trunc(v) || v > math.MaxInt32 || v < math.MinInt32 {
+			return 0, true, &EventValidationError{Field: key, Message: fmt.Sprintf("%v is not an integer", v)}
+		}
+		count = int(v)
+	case string:
+		parsed, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return 0, true, &EventValidationError{Field: key, Message: fmt.Sprintf("%q is not an integer", v)}
+		}
+		count = parsed
+	default:
+		return 0, true, &EventValidationError{Field: key, Message: fmt.Sprintf("expected a number, got %v", v)}
+	}
+
+	if count < 0 {
+		return 0, true, &EventValidationError{Field: key, Message: fmt.Sprintf("%d is negative", count)}
+	}
+	return count, true, nil
+}
 
 // convertActionsToDCSResponse converts internal actions to DCS response format
 func convertActionsToDCSResponse(actions []models.Action) DCSResponse {
-    response := DCSResponse{
-        Status:  "success",
-        Actions: make([]DCSAction, 0, len(actions)),
-    }
-
-    for _, action := range actions {
-        dcsAction := DCSAction{
-            ActionType: action.Type,
-            SubType:    action.SubType,
-            Data:       make(map[string]interface{}),
-        }
-
-        // Convert each action type to the appropriate DCS action format
-        switch action.Type {
-        case "spawn":
-            dcsAction.Data["zone"] = action.Zone
-            dcsAction.Data["unit_type"] = action.UnitType
-            dcsAction.Data["count"] = action.Count
-
-        case "alert":
-            dcsAction.Data["level"] = action.Level
-            dcsAction.Data["message"] = action.Message
-            if action.Zone != "" {
-                dcsAction.Data["zone"] = action.Zone
-            }
-
-        case "reinforce":
-            dcsAction.Data["unit_type"] = action.UnitType
-            dcsAction.Data["group_name"] = action.GroupName
-            dcsAction.Data["zone"] = action.Zone
-            dcsAction.Data["count"] = action.Count
-        }
-
-        response.Actions = append(response.Actions, dcsAction)
-    }
-
-    return response
+	response := DCSResponse{
+		Status:       StatusSuccess,
+		Actions:      make([]DCSAction, 0, len(actions)),
+		MatchedRules: make([]string, 0),
+	}
+
+	for _, action := range actions {
+		response.Actions = append(response.Actions, convertActionToDCSAction(action))
+	}
+
+	return response
+}
+
+// convertResultToDCSResponse converts an evaluation result to DCS response format,
+// optionally including the per-cycle execution trace
+func convertResultToDCSResponse(result *rules.EvaluationResult, includeTrace bool) DCSResponse {
+	response := convertActionsToDCSResponse(result.Actions)
+	response.MatchedRules = result.MatchedRules
+
+	// Scheduled actions that came due are delivered like the others
+	for _, due := range result.Due {
+		response.Actions = append(response.Actions, convertActionToDCSAction(due.Action))
+	}
+	for _, scheduled := range result.Scheduled {
+		response.Scheduled = append(response.Scheduled, convertScheduledAction(scheduled))
+	}
+
+	for _, rejected := range result.Rejected {
+		response.Rejected = append(response.Rejected, DCSRejectedAction{
+			Rule:     rejected.Rule,
+			Action:   convertActionToDCSAction(rejected.Action),
+			Problems: rejected.Problems,
+		})
+	}
+
+	if includeTrace {
+		response.Trace = make([]DCSTraceEntry, 0, len(result.Trace))
+		for _, firing := range result.Trace {
+			entry := DCSTraceEntry{
+				Rule:    firing.Rule,
+				Cycle:   firing.Cycle,
+				Derived: firing.Derived,
+				Actions: make([]DCSAction, 0, len(firing.Actions)),
+			}
+			for _, action := range firing.Actions {
+				entry.Actions = append(entry.Actions, convertActionToDCSAction(action))
+			}
+			for _, scheduled := range firing.Scheduled {
+				entry.Scheduled = append(entry.Scheduled, convertScheduledAction(scheduled))
+			}
+			for _, suppressed := range firing.Suppressed {
+				entry.Suppressed = append(entry.Suppressed, DCSSuppressedAction{
+					Action: convertActionToDCSAction(suppressed.Action),
+					Reason: suppressed.Reason,
+				})
+			}
+			response.Trace = append(response.Trace, entry)
+		}
+	}
+
+	return response
+}
+
+// buildDCSResponse converts the outcome of an evaluation to DCS response format.
+// Evaluation errors are reported in the response rather than as plain HTTP errors
+// so that DCS can tell a partial result from a failed one.
+func buildDCSResponse(result *rules.EvaluationResult, err error, includeTrace bool) DCSResponse {
+	var response DCSResponse
+	if result != nil {
+		response = convertResultToDCSResponse(result, includeTrace)
+	} else {
+		response = convertActionsToDCSResponse(nil)
+	}
+
+	if err == nil {
+		return response
+	}
+
+	var cycleErr *rules.CycleExhaustedError
+	var runtimeErr *rules.RuleRuntimeError
+	var conditionErr *rules.RuleConditionError
+	var contextErr *rules.DataContextError
+	var validationErr *EventValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		response.Error = &DCSError{Kind: "validation", Message: err.Error()}
+	case errors.As(err, &cycleErr):
+		response.Error = &DCSError{Kind: "cycle_exhausted", Rule: cycleErr.Rule, Message: err.Error()}
+	case errors.As(err, &conditionErr):
+		response.Error = &DCSError{Kind: "rule_condition", Rule: conditionErr.Rule, Message: err.Error()}
+	case errors.As(err, &runtimeErr):
+		response.Error = &DCSError{Kind: "rule_runtime", Rule: runtimeErr.Rule, Message: err.Error()}
+	case errors.As(err, &contextErr):
+		response.Error = &DCSError{Kind: "data_context", Message: err.Error()}
+	default:
+		response.Error = &DCSError{Kind: "internal", Message: err.Error()}
+	}
+
+	response.Status = StatusPartial
+	if result == nil {
+		response.Status = StatusError
+	}
+
+	if response.Error.Rule != "" {
+		log.Printf("Evaluation %s: rule %s: %s", response.Status, response.Error.Rule, response.Error.Message)
+	} else {
+		log.Printf("Evaluation %s: %s", response.Status, response.Error.Message)
+	}
+
+	return response
+}
+
+// writeDCSResponse sends a DCS response as JSON. Invalid events are sent with
+// status 400 and other failed evaluations with status 500.
+func writeDCSResponse(w http.ResponseWriter, response DCSResponse) error {
+	w.Header().Set("Content-Type", "application/json")
+	if response.Status == StatusError {
+		if response.Error != nil && response.Error.Kind == "validation" {
+			w.WriteHeader(http.StatusBadRequest)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+	return json.NewEncoder(w).Encode(response)
 }
+
+// convertActionToDCSAction converts a single internal action to DCS action format
+func convertActionToDCSAction(action models.Action) DCSAction {
+	return DCSAction{
+		ID:         action.ID,
+		ActionType: action.Type,
+		SubType:    action.SubType,
+		Data:       action.Data(),
+	}
+}
+
+// wantsTrace reports whether the request asked for the execution trace (?trace=true)
+func wantsTrace(r *http.Request) bool {
+	trace, err := strconv.ParseBool(r.URL.Query().Get("trace"))
+	return err == nil && trace
+}
+
 // in internal/api/handlers.go
 // Add a function to batch process messages
 
 // BatchProcessEvents processes multiple events at once
-func BatchProcessEvents(ruleEngine *rules.RuleEngine, dcsEvents []DCSEvent) ([]models.Action, error) {
-    var messages []*models.Message
-    
-    // Convert all events to messages
-    for _, event := range dcsEvents {
-        message := convertDCSEventToMessage(event)
-        messages = append(messages, message)
-    }
-    
-    // Process all messages at once
-    return ruleEngine.ProcessMessages(messages)
+func BatchProcessEvents(ruleEngine *rules.RuleEngine, dcsEvents []DCSEvent) (*rules.EvaluationResult, error) {
+	var messages []*models.Message
+
+	// Convert all events to messages
+	for i, event := range dcsEvents {
+		if event.EventType == AckEventType {
+			return nil, fmt.Errorf("event %d: %w", i, &EventValidationError{
+				Field:   "event_type",
+				Message: AckEventType + " events must be sent on their own, not in a batch",
+			})
+		}
+		message, err := convertDCSEventToMessage(event)
+		if err != nil {
+			return nil, fmt.Errorf("event %d: %w", i, err)
+		}
+		logMessage(ruleEngine, message)
+		if len(messages) > 0 && message.MissionID != messages[0].MissionID {
+			return nil, fmt.Errorf("event %d: %w", i, &EventValidationError{
+				Field:   "mission_id",
+				Message: fmt.Sprintf("%q differs from %q of event 0, a batch must belong to one mission", message.MissionID, messages[0].MissionID),
+			})
+		}
+		messages = append(messages, message)
+	}
+
+	// Process all messages at once
+	return ruleEngine.EvaluateMessages(messages)
+}
+
+// ProcessEvent evaluates a single DCS event the same way the event endpoint does
+func ProcessEvent(ruleEngine *rules.RuleEngine, dcsEvent DCSEvent, includeTrace bool) DCSResponse {
+	if dcsEvent.EventType == AckEventType {
+		return ProcessAck(ruleEngine, dcsEvent, includeTrace)
+	}
+	message, err := convertDCSEventToMessage(dcsEvent)
+	if err != nil {
+		return buildDCSResponse(nil, err, includeTrace)
+	}
+	logMessage(ruleEngine, message)
+	result, err := ruleEngine.EvaluateMessage(message)
+	return buildDCSResponse(result, err, includeTrace)
+}
+
+// ProcessBatch evaluates DCS events together the same way the batch endpoint does
+func ProcessBatch(ruleEngine *rules.RuleEngine, dcsEvents []DCSEvent, includeTrace bool) DCSResponse {
+	result, err := BatchProcessEvents(ruleEngine, dcsEvents)
+	return buildDCSResponse(result, err, includeTrace)
 }
 
 // Add to handlers.go
 // BatchDCSEventHandler handles batches of DCS events
 func BatchDCSEventHandler(ruleEngine *rules.RuleEngine) http.HandlerFunc {
-    return func(w http.ResponseWriter, r *http.Request) {
-        // Parse the incoming JSON array
-        var dcsEvents []DCSEvent
-        if err := json.NewDecoder(r.Body).Decode(&dcsEvents); err != nil {
-            http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
-            return
-        }
-
-        fmt.Printf("Received batch of %d events\n", len(dcsEvents))
-        for i, event := range dcsEvents {
-            fmt.Printf("Event %d: Type=%s, Zone=%s\n", i, event.EventType, 
-                event.Data["zone"])
-        }
-
-        // Process all events at once
-        actions, err := BatchProcessEvents(ruleEngine, dcsEvents)
-        if err != nil {
-            http.Error(w, "Rule processing failed: "+err.Error(), http.StatusInternalServerError)
-            return
-        }
-
-        // Convert actions to DCS response format
-        dcsResponse := convertActionsToDCSResponse(actions)
-        
-        fmt.Printf("Response: %+v\n", dcsResponse)
-
-        // Send response back to DCS
-        w.Header().Set("Content-Type", "application/json")
-        if err := json.NewEncoder(w).Encode(dcsResponse); err != nil {
-            http.Error(w, "Failed to encode response: "+err.Error(), http.StatusInternalServerError)
-            return
-        }
-        
-        fmt.Println("Response sent successfully")
-    }
-}
\ No newline at end of file
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse the incoming JSON array
+		var dcsEvents []DCSEvent
+		if err := json.NewDecoder(r.Body).Decode(&dcsEvents); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		fmt.Printf("Received batch of %d events\n", len(dcsEvents))
+		for i, event := range dcsEvents {
+			fmt.Printf("Event %d: Type=%s, Zone=%s\n", i, event.EventType,
+				event.Data["zone"])
+		}
+
+		// Process all events at once
+		dcsResponse := ProcessBatch(ruleEngine, dcsEvents, wantsTrace(r))
+
+		fmt.Printf("Response: %+v\n", dcsResponse)
+
+		// Send response back to DCS
+		if err := writeDCSResponse(w, dcsResponse); err != nil {
+			log.Printf("Failed to encode response: %v", err)
+			return
+		}
+
+		fmt.Println("Response sent successfully")
+	}
+}
@@ -0,0 +1,231 @@
+// internal/api/handlers_test.go
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bass4/dcs-ice/internal/config"
+	"github.com/bass4/dcs-ice/internal/rules"
+	"github.com/bass4/dcs-ice/pkg/models"
+)
+
+func init() {
+	rules.SetGruleLogger(io.Discard, "error")
+}
+
+// newTestEngine loads rules from a temporary directory into an engine that
+// reports on out
+func newTestEngine(t *testing.T, grl string, out io.Writer) *rules.RuleEngine {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "rules.grl"), []byte(grl), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ruleEngine, err := rules.NewRuleEngineWithOutput(&config.Config{RulesDirs: []string{dir}, MaxCycles: 10}, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ruleEngine
+}
+
+func TestBuildDCSResponse(t *testing.T) {
+	partial := &rules.EvaluationResult{Actions: []models.Action{{Type: "alert", Level: "red"}}}
+
+	tests := []struct {
+		name   string
+		result *rules.EvaluationResult
+		err    error
+		status string
+		kind   string
+		rule   string
+		code   int
+	}{
+		{"success", partial, nil, StatusSuccess, "", "", http.StatusOK},
+		{"invalid event", nil, &EventValidationError{Field: "count", Message: "not an integer"}, StatusError, "validation", "", http.StatusBadRequest},
+		{"cycles exhausted", partial, &rules.CycleExhaustedError{Rule: "Loop", MaxCycles: 5}, StatusPartial, "cycle_exhausted", "Loop", http.StatusOK},
+		{"when failed", partial, &rules.RuleConditionError{Rule: "Broken", Err: errors.New("no method")}, StatusPartial, "rule_condition", "Broken", http.StatusOK},
+		{"then failed", partial, &rules.RuleRuntimeError{Rule: "Panics", Err: errors.New("boom")}, StatusPartial, "rule_runtime", "Panics", http.StatusOK},
+		{"facts not set up", nil, &rules.DataContextError{Key: "World", Err: errors.New("nil")}, StatusError, "data_context", "", http.StatusInternalServerError},
+		{"unknown error", nil, errors.New("boom"), StatusError, "internal", "", http.StatusInternalServerError},
+		{"derived message failed", partial, rules.EvaluationErrors{errors.New("boom"), &rules.RuleConditionError{Rule: "OnStrike", Err: errors.New("no field")}}, StatusPartial, "rule_condition", "OnStrike", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := buildDCSResponse(tt.result, tt.err, false)
+			if response.Status != tt.status {
+				t.Errorf("status %q, want %q", response.Status, tt.status)
+			}
+			if tt.kind == "" {
+				if response.Error != nil {
+					t.Errorf("unexpected error %+v", response.Error)
+				}
+			} else if response.Error == nil || response.Error.Kind != tt.kind || response.Error.Rule != tt.rule {
+				t.Errorf("error %+v, want kind %q and rule %q", response.Error, tt.kind, tt.rule)
+			}
+			if tt.result != nil && len(response.Actions) != len(tt.result.Actions) {
+				t.Errorf("got %d actions, want %d", len(response.Actions), len(tt.result.Actions))
+			}
+
+			recorder := httptest.NewRecorder()
+			if err := writeDCSResponse(recorder, response); err != nil {
+				t.Fatal(err)
+			}
+			if recorder.Code != tt.code {
+				t.Errorf("HTTP %d, want %d", recorder.Code, tt.code)
+			}
+		})
+	}
+}
+
+func TestConvertDCSEventCountAndLevel(t *testing.T) {
+	tests := []struct {
+		name  string
+		event string
+		data  map[string]interface{}
+		count int
+		level models.AlertLevel
+		field string // Field of the expected *EventValidationError
+	}{
+		{"count as number", "unit_destroyed", map[string]interface{}{"count": 3.0}, 3, "", ""},
+		{"count as string", "unit_destroyed", map[string]interface{}{"count": " 4 "}, 4, "", ""},
+		{"detection defaults to one unit", "unit_detected", map[string]interface{}{}, 1, "", ""},
+		{"explicit zero detections", "unit_detected", map[string]interface{}{"count": 0.0}, 0, "", ""},
+		{"other events default to zero", "unit_destroyed", map[string]interface{}{}, 0, "", ""},
+		{"fractional count", "unit_detected", map[string]interface{}{"count": 2.5}, 0, "", "count"},
+		{"negative count", "unit_detected", map[string]interface{}{"count": -1.0}, 0, "", "count"},
+		{"non-numeric count", "unit_detected", map[string]interface{}{"count": "many"}, 0, "", "count"},
+		{"boolean count", "unit_detected", map[string]interface{}{"count": true}, 0, "", "count"},
+		{"level in any case", "alert_level_change", map[string]interface{}{"level": "RED"}, 0, models.AlertLevelRed, ""},
+		{"unknown level", "alert_level_change", map[string]interface{}{"level": "orange"}, 0, "", "level"},
+		{"level as number", "alert_level_change", map[string]interface{}{"level": 2.0}, 0, "", "level"},
+		{"level change without level", "alert_level_change", map[string]interface{}{}, 0, "", "level"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := convertDCSEventToMessage(DCSEvent{EventType: tt.event, Data: tt.data})
+			if tt.field != "" {
+				var validationErr *EventValidationError
+				if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+					t.Fatalf("got error %v, want an invalid %s", err, tt.field)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if message.Count != tt.count || message.Level != tt.level {
+				t.Errorf("count %d and level %q, want %d and %q", message.Count, message.Level, tt.count, tt.level)
+			}
+			if message.MissionID != models.DefaultMissionID {
+				t.Errorf("mission %q, want %q", message.MissionID, models.DefaultMissionID)
+			}
+		})
+	}
+}
+
+func TestConvertDCSEventKeepsPayload(t *testing.T) {
+	data := map[string]interface{}{
+		"zone":      "ALPHA",
+		"unit_type": "F-16C",
+		"weapon":    map[string]interface{}{"name": "GBU-12", "guided": true},
+	}
+	message, err := convertDCSEventToMessage(DCSEvent{MissionID: "op-1", EventType: "weapon_fired", Timestamp: 42, Data: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, got, want string
+	}{
+		{"mission", message.MissionID, "op-1"},
+		{"zone field", message.Zone, "ALPHA"},
+		{"zone in the payload", message.GetString("zone"), "ALPHA"},
+		{"unit type in the payload", message.GetString("unit_type"), "F-16C"},
+		{"nested field", message.GetString("weapon.name"), "GBU-12"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+	if !message.GetBool("weapon.guided") || message.Timestamp != 42 {
+		t.Errorf("payload or timestamp lost: %+v", message)
+	}
+}
+
+func TestConvertDCSEventPosition(t *testing.T) {
+	tests := []struct {
+		name     string
+		position interface{}
+		want     models.Position
+		has      bool
+		field    string // Field of the expected *EventValidationError
+	}{
+		{"numbers", map[string]interface{}{"x": 1.5, "y": 200.0, "z": -3.0}, models.Position{X: 1.5, Y: 200, Z: -3}, true, ""},
+		{"numeric strings", map[string]interface{}{"x": "1.5", "y": " 200 ", "z": "-3"}, models.Position{X: 1.5, Y: 200, Z: -3}, true, ""},
+		{"null", nil, models.Position{}, false, ""},
+		{"not an object", "1,2,3", models.Position{}, false, "position"},
+		{"missing axis", map[string]interface{}{"x": 1.0, "y": 2.0}, models.Position{}, false, "position.z"},
+		{"non-numeric axis", map[string]interface{}{"x": "north", "y": 2.0, "z": 3.0}, models.Position{}, false, "position.x"},
+		{"boolean axis", map[string]interface{}{"x": 1.0, "y": true, "z": 3.0}, models.Position{}, false, "position.y"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := convertDCSEventToMessage(DCSEvent{EventType: "unit_detected", Data: map[string]interface{}{"position": tt.position}})
+			if tt.field != "" {
+				var validationErr *EventValidationError
+				if !errors.As(err, &validationErr) || validationErr.Field != tt.field {
+					t.Fatalf("got error %v, want an invalid %s", err, tt.field)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if message.Position != tt.want || message.HasPosition != tt.has {
+				t.Errorf("position %+v (%v), want %+v (%v)", message.Position, message.HasPosition, tt.want, tt.has)
+			}
+		})
+	}
+}
+
+func TestConvertDCSEventCoalition(t *testing.T) {
+	tests := []struct {
+		name      string
+		coalition interface{}
+		want      models.Coalition
+		invalid   bool
+	}{
+		{"name", "Blue", models.CoalitionBlue, false},
+		{"DCS number", 1.0, models.CoalitionRed, false},
+		{"DCS number as string", "0", models.CoalitionNeutral, false},
+		{"null", nil, "", false},
+		{"unknown name", "purple", "", true},
+		{"unknown number", 5.0, "", true},
+		{"boolean", true, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := convertDCSEventToMessage(DCSEvent{EventType: "unit_detected", Data: map[string]interface{}{"coalition": tt.coalition}})
+			if tt.invalid {
+				var validationErr *EventValidationError
+				if !errors.As(err, &validationErr) || validationErr.Field != "coalition" {
+					t.Fatalf("got error %v, want an invalid coalition", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if message.Coalition != tt.want {
+				t.Errorf("coalition %q, want %q", message.Coalition, tt.want)
+			}
+		})
+	}
+}
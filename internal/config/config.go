@@ -9,6 +9,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/bass4/dcs-ice/pkg/models"
 )
 
 // Config holds all configuration options for the application
@@ -20,6 +22,25 @@ type Config struct {
 	// Rules settings
 	RulesDirs     []string `json:"rules_dirs"`
 	RulesFiles    []string `json:"rules_files"`
+	WatchRules    bool     `json:"watch_rules"`       // Reload automatically when rule files change
+	WatchDebounce int      `json:"watch_debounce_ms"` // Quiet period after the last change before reloading
+	
+	// Zone settings
+	Zones         []ZoneConfig `json:"zones"`      // Zones defined inline
+	ZonesFile     string       `json:"zones_file"` // JSON file with an array of additional zones
+	
+	// Mission state settings
+	HistoryWindow int             `json:"history_window_minutes"` // Minutes of mission time kept in each mission's message history
+	Patterns      []PatternConfig `json:"patterns"`               // Event patterns defined inline
+	PatternsFile  string          `json:"patterns_file"`          // JSON file with an array of additional patterns
+	MissionIdle   int             `json:"mission_idle_minutes"`   // Minutes of wall time after which an unused mission's state is dropped; 0 keeps it
+	
+	// Action settings
+	ActionSchemas     []ActionSchemaConfig `json:"action_schemas"`       // Schemas of additional action types, or overrides of the built-in ones
+	ActionSchemasFile string               `json:"action_schemas_file"`  // JSON file with an array of additional action schemas
+	ActionLimits      []ActionLimitConfig  `json:"action_limits"`        // Cooldowns and budgets for actions
+	ActionLimitsFile  string               `json:"action_limits_file"`   // JSON file with an array of additional action limits
+	ActionDedup       int64                `json:"action_dedup_seconds"` // Suppress an action identical to one sent this many seconds of mission time before; 0 disables
 	
 	// Logging settings
 	LogLevel      string   `json:"log_level"`
@@ -30,16 +51,101 @@ type Config struct {
 	ConfigFile    string   // Not stored in JSON, used for command line only
 }
 
+// ZoneConfig defines a named zone in DCS x/z coordinates. Exactly one of
+// Circle and Polygon must be set.
+type ZoneConfig struct {
+	Name        string        `json:"name"`
+	Circle      *CircleConfig `json:"circle,omitempty"`
+	Polygon     []PointConfig `json:"polygon,omitempty"`
+	MinAltitude *float64      `json:"min_altitude,omitempty"` // Optional altitude band in metres
+	MaxAltitude *float64      `json:"max_altitude,omitempty"`
+}
+
+// CircleConfig is a circular zone around a center point
+type CircleConfig struct {
+	X      float64 `json:"x"`
+	Z      float64 `json:"z"`
+	Radius float64 `json:"radius"`
+}
+
+// PointConfig is a polygon vertex
+type PointConfig struct {
+	X float64 `json:"x"`
+	Z float64 `json:"z"`
+}
+
+// PatternConfig defines an event pattern. When its steps are seen in order
+// within WithinSeconds (and, if Absent is set, the absent event does not
+// follow), a derived message with event type Emit is produced.
+type PatternConfig struct {
+	Name          string               `json:"name"`
+	Emit          string               `json:"emit,omitempty"` // Defaults to Name
+	Steps         []PatternStepConfig  `json:"steps"`
+	WithinSeconds int64                `json:"within_seconds,omitempty"` // Maximum time from the first to the last step
+	Absent        *PatternAbsentConfig `json:"absent,omitempty"`
+	CorrelateBy   string               `json:"correlate_by,omitempty"` // unit_name, group_name or any data path; empty matches any events
+}
+
+// PatternStepConfig matches one event of a pattern. Empty fields match anything.
+type PatternStepConfig struct {
+	Event     string            `json:"event"`
+	Zone      string            `json:"zone,omitempty"`
+	Coalition string            `json:"coalition,omitempty"`
+	Match     map[string]string `json:"match,omitempty"` // Data paths and the string values they must have
+}
+
+// PatternAbsentConfig is an event that must not follow the steps within WithinSeconds
+type PatternAbsentConfig struct {
+	PatternStepConfig
+	WithinSeconds int64 `json:"within_seconds"`
+}
+
+// ActionSchemaConfig declares the parameters of an action type
+type ActionSchemaConfig struct {
+	Type     string              `json:"type"`
+	SubTypes []string            `json:"sub_types,omitempty"` // Allowed sub types; empty allows any
+	Params   []ActionParamConfig `json:"params"`
+}
+
+// ActionParamConfig declares one parameter of an action type
+type ActionParamConfig struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"` // string, integer, number or boolean
+	Required bool     `json:"required,omitempty"`
+	Enum     []string `json:"enum,omitempty"` // Allowed values of a string parameter
+}
+
+// ActionLimitConfig limits the actions matching its filters within a mission.
+// Empty filters match anything. Per lists the fields (rule, action_type,
+// sub_type, zone, unit_type, group_name) that get separate counters, so
+// "per": ["zone"] gives every zone its own cooldown.
+type ActionLimitConfig struct {
+	Name            string   `json:"name"`
+	Rule            string   `json:"rule,omitempty"`
+	ActionType      string   `json:"action_type,omitempty"`
+	SubType         string   `json:"sub_type,omitempty"`
+	Zone            string   `json:"zone,omitempty"`
+	UnitType        string   `json:"unit_type,omitempty"`
+	Per             []string `json:"per,omitempty"`
+	CooldownSeconds int64    `json:"cooldown_seconds,omitempty"` // Mission time after a matching action during which further ones are suppressed
+	MaxActions      int      `json:"max_actions,omitempty"`      // Matching actions allowed per mission
+	MaxUnits        int      `json:"max_units,omitempty"`        // Sum of the counts of matching actions allowed per mission
+}
+
 // DefaultConfig returns a config with default values
 func DefaultConfig() *Config {
 	return &Config{
-		Host:       "0.0.0.0",
-		Port:       8080,
-		RulesDirs:  []string{"config/rules"},
-		RulesFiles: []string{},
-		LogLevel:   "info",
-		LogFile:    "",  // Empty means stdout
-		MaxCycles:  5,
+		Host:          "0.0.0.0",
+		Port:          8080,
+		RulesDirs:     []string{"config/rules"},
+		RulesFiles:    []string{},
+		WatchRules:    false,
+		WatchDebounce: 500,
+		HistoryWindow: 5,
+		MissionIdle:   120,
+		LogLevel:      "info",
+		LogFile:       "",  // Empty means stdout
+		MaxCycles:     5,
 	}
 }
 
@@ -49,22 +155,23 @@ func DefaultConfig() *Config {
 // 3. Configuration file
 // 4. Default values (lowest)
 func LoadConfig() (*Config, error) {
+	return LoadConfigFromArgs(os.Args[1:], nil)
+}
+
+// LoadConfigFromArgs is like LoadConfig but parses the given arguments.
+// If extraFlags is not nil it is called to define additional flags, such as
+// those of a subcommand, on the flag set before the arguments are parsed.
+func LoadConfigFromArgs(args []string, extraFlags func(*flag.FlagSet)) (*Config, error) {
 	// Start with defaults
 	config := DefaultConfig()
 	
-	// Parse command-line flags but don't fail on unknown flags yet
-	cmdConfig := flag.NewFlagSet("config", flag.ContinueOnError)
-	cmdConfig.SetOutput(os.Stdout)
-	
-	// Define flags but don't process them yet
-	configFile := cmdConfig.String("config", "", "Path to configuration file")
-	// Just parse the config flag first to see if we need to load a config file
-	cmdConfig.Parse(os.Args[1:])
+	// Look for the config flag first to see if we need to load a config file
+	configFile := configFileFromArgs(args)
 	
 	// Load from config file if specified
-	if *configFile != "" {
-		config.ConfigFile = *configFile
-		if err := config.loadFromFile(*configFile); err != nil {
+	if configFile != "" {
+		config.ConfigFile = configFile
+		if err := config.loadFromFile(configFile); err != nil {
 			return nil, fmt.Errorf("error loading config file: %v", err)
 		}
 	}
@@ -72,8 +179,8 @@ func LoadConfig() (*Config, error) {
 	// Load from environment variables
 	config.loadFromEnv()
 	
-	// Reset the flags parser to process all flags
-	cmdConfig = flag.NewFlagSet("config", flag.ExitOnError)
+	// Parse all flags
+	cmdConfig := flag.NewFlagSet("config", flag.ExitOnError)
 	
 	// Server settings
 	cmdHost := cmdConfig.String("host", config.Host, "Host to listen on")
@@ -82,6 +189,21 @@ func LoadConfig() (*Config, error) {
 	// Rules settings
 	cmdRulesDirs := cmdConfig.String("rules-dirs", strings.Join(config.RulesDirs, ","), "Comma-separated list of rules directories")
 	cmdRulesFiles := cmdConfig.String("rules-files", strings.Join(config.RulesFiles, ","), "Comma-separated list of specific rule files")
+	cmdWatchRules := cmdConfig.Bool("watch-rules", config.WatchRules, "Reload rules automatically when rule files change")
+	cmdWatchDebounce := cmdConfig.Int("watch-debounce-ms", config.WatchDebounce, "Milliseconds to wait after the last rule file change before reloading")
+	
+	// Zone settings
+	cmdZonesFile := cmdConfig.String("zones-file", config.ZonesFile, "JSON file with an array of zone definitions")
+	
+	// Mission state settings
+	cmdHistoryWindow := cmdConfig.Int("history-window-minutes", config.HistoryWindow, "Minutes of mission time kept in each mission's message history")
+	cmdPatternsFile := cmdConfig.String("patterns-file", config.PatternsFile, "JSON file with an array of event pattern definitions")
+	cmdMissionIdle := cmdConfig.Int("mission-idle-minutes", config.MissionIdle, "Minutes after which the state of a mission no event or request used is dropped (0 keeps it)")
+	
+	// Action settings
+	cmdActionSchemasFile := cmdConfig.String("action-schemas-file", config.ActionSchemasFile, "JSON file with an array of action schemas")
+	cmdActionLimitsFile := cmdConfig.String("action-limits-file", config.ActionLimitsFile, "JSON file with an array of action limits")
+	cmdActionDedup := cmdConfig.Int64("action-dedup-seconds", config.ActionDedup, "Seconds of mission time within which identical actions are suppressed (0 disables)")
 	
 	// Logging settings
 	cmdLogLevel := cmdConfig.String("log-level", config.LogLevel, "Log level (debug, info, warn, error)")
@@ -93,8 +215,12 @@ func LoadConfig() (*Config, error) {
 	// Redundant config file flag
 	cmdConfig.String("config", config.ConfigFile, "Path to configuration file")
 	
+	if extraFlags != nil {
+		extraFlags(cmdConfig)
+	}
+	
 	// Parse command line arguments, overriding previous values
-	cmdConfig.Parse(os.Args[1:])
+	cmdConfig.Parse(args)
 	
 	// Apply command line values if explicitly provided
 	if cmdConfig.Lookup("host").Value.String() != config.Host {
@@ -109,6 +235,33 @@ func LoadConfig() (*Config, error) {
 	if cmdConfig.Lookup("rules-files").Value.String() != strings.Join(config.RulesFiles, ",") {
 		config.RulesFiles = splitAndTrim(*cmdRulesFiles)
 	}
+	if cmdConfig.Lookup("watch-rules").Value.String() != strconv.FormatBool(config.WatchRules) {
+		config.WatchRules = *cmdWatchRules
+	}
+	if cmdConfig.Lookup("watch-debounce-ms").Value.String() != fmt.Sprintf("%d", config.WatchDebounce) {
+		config.WatchDebounce = *cmdWatchDebounce
+	}
+	if cmdConfig.Lookup("zones-file").Value.String() != config.ZonesFile {
+		config.ZonesFile = *cmdZonesFile
+	}
+	if cmdConfig.Lookup("history-window-minutes").Value.String() != fmt.Sprintf("%d", config.HistoryWindow) {
+		config.HistoryWindow = *cmdHistoryWindow
+	}
+	if cmdConfig.Lookup("patterns-file").Value.String() != config.PatternsFile {
+		config.PatternsFile = *cmdPatternsFile
+	}
+	if cmdConfig.Lookup("mission-idle-minutes").Value.String() != fmt.Sprintf("%d", config.MissionIdle) {
+		config.MissionIdle = *cmdMissionIdle
+	}
+	if cmdConfig.Lookup("action-schemas-file").Value.String() != config.ActionSchemasFile {
+		config.ActionSchemasFile = *cmdActionSchemasFile
+	}
+	if cmdConfig.Lookup("action-limits-file").Value.String() != config.ActionLimitsFile {
+		config.ActionLimitsFile = *cmdActionLimitsFile
+	}
+	if cmdConfig.Lookup("action-dedup-seconds").Value.String() != fmt.Sprintf("%d", config.ActionDedup) {
+		config.ActionDedup = *cmdActionDedup
+	}
 	if cmdConfig.Lookup("log-level").Value.String() != config.LogLevel {
 		config.LogLevel = *cmdLogLevel
 	}
@@ -119,9 +272,59 @@ func LoadConfig() (*Config, error) {
 		config.MaxCycles = *cmdMaxCycles
 	}
 	
+	// Add the zones, patterns, action schemas and action limits from their files to those defined inline
+	if config.ZonesFile != "" {
+		var zones []ZoneConfig
+		if err := loadJSONFile(config.ZonesFile, &zones); err != nil {
+			return nil, fmt.Errorf("error loading zones file: %v", err)
+		}
+		config.Zones = append(config.Zones, zones...)
+	}
+	if config.PatternsFile != "" {
+		var patterns []PatternConfig
+		if err := loadJSONFile(config.PatternsFile, &patterns); err != nil {
+			return nil, fmt.Errorf("error loading patterns file: %v", err)
+		}
+		config.Patterns = append(config.Patterns, patterns...)
+	}
+	if config.ActionSchemasFile != "" {
+		var schemas []ActionSchemaConfig
+		if err := loadJSONFile(config.ActionSchemasFile, &schemas); err != nil {
+			return nil, fmt.Errorf("error loading action schemas file: %v", err)
+		}
+		config.ActionSchemas = append(config.ActionSchemas, schemas...)
+	}
+	if config.ActionLimitsFile != "" {
+		var limits []ActionLimitConfig
+		if err := loadJSONFile(config.ActionLimitsFile, &limits); err != nil {
+			return nil, fmt.Errorf("error loading action limits file: %v", err)
+		}
+		config.ActionLimits = append(config.ActionLimits, limits...)
+	}
+	
 	return config, validateConfig(config)
 }
 
+// configFileFromArgs returns the value of the -config flag, if present
+func configFileFromArgs(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name := strings.TrimLeft(arg, "-")
+		if name == arg {
+			continue
+		}
+		if name == "config" && i+1 < len(args) {
+			return args[i+1]
+		}
+		if strings.HasPrefix(name, "config=") {
+			return strings.TrimPrefix(name, "config=")
+		}
+	}
+	return ""
+}
+
 // loadFromFile loads configuration from a JSON file
 func (c *Config) loadFromFile(filePath string) error {
 	file, err := os.Open(filePath)
@@ -134,6 +337,15 @@ func (c *Config) loadFromFile(filePath string) error {
 	return decoder.Decode(c)
 }
 
+// loadJSONFile decodes a JSON file into v
+func loadJSONFile(filePath string, v interface{}) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
 // loadFromEnv loads configuration from environment variables
 func (c *Config) loadFromEnv() {
 	// Helper function to get env var if it exists
@@ -161,6 +373,49 @@ func (c *Config) loadFromEnv() {
 	if rulesFiles := getEnv("DCS_ICE_RULES_FILES", ""); rulesFiles != "" {
 		c.RulesFiles = splitAndTrim(rulesFiles)
 	}
+	if watchRules := getEnv("DCS_ICE_WATCH_RULES", ""); watchRules != "" {
+		if w, err := strconv.ParseBool(watchRules); err == nil {
+			c.WatchRules = w
+		}
+	}
+	if watchDebounce := getEnv("DCS_ICE_WATCH_DEBOUNCE_MS", ""); watchDebounce != "" {
+		if d, err := strconv.Atoi(watchDebounce); err == nil {
+			c.WatchDebounce = d
+		}
+	}
+	
+	// Zone settings
+	if zonesFile := getEnv("DCS_ICE_ZONES_FILE", ""); zonesFile != "" {
+		c.ZonesFile = zonesFile
+	}
+	
+	// Mission state settings
+	if historyWindow := getEnv("DCS_ICE_HISTORY_WINDOW_MINUTES", ""); historyWindow != "" {
+		if h, err := strconv.Atoi(historyWindow); err == nil {
+			c.HistoryWindow = h
+		}
+	}
+	if patternsFile := getEnv("DCS_ICE_PATTERNS_FILE", ""); patternsFile != "" {
+		c.PatternsFile = patternsFile
+	}
+	if missionIdle := getEnv("DCS_ICE_MISSION_IDLE_MINUTES", ""); missionIdle != "" {
+		if m, err := strconv.Atoi(missionIdle); err == nil {
+			c.MissionIdle = m
+		}
+	}
+	
+	// Action settings
+	if actionSchemasFile := getEnv("DCS_ICE_ACTION_SCHEMAS_FILE", ""); actionSchemasFile != "" {
+		c.ActionSchemasFile = actionSchemasFile
+	}
+	if actionLimitsFile := getEnv("DCS_ICE_ACTION_LIMITS_FILE", ""); actionLimitsFile != "" {
+		c.ActionLimitsFile = actionLimitsFile
+	}
+	if actionDedup := getEnv("DCS_ICE_ACTION_DEDUP_SECONDS", ""); actionDedup != "" {
+		if d, err := strconv.ParseInt(actionDedup, 10, 64); err == nil {
+			c.ActionDedup = d
+		}
+	}
 	
 	// Logging settings
 	if logLevel := getEnv("DCS_ICE_LOG_LEVEL", ""); logLevel != "" {
@@ -230,6 +485,141 @@ func validateConfig(c *Config) error {
 		}
 	}
 	
+	// Validate watch debounce
+	if c.WatchDebounce < 0 {
+		return fmt.Errorf("watch debounce must not be negative")
+	}
+	
+	// Validate zones
+	zoneNames := make(map[string]bool)
+	for i, zone := range c.Zones {
+		if zone.Name == "" {
+			return fmt.Errorf("zone %d has no name", i+1)
+		}
+		if zoneNames[zone.Name] {
+			return fmt.Errorf("duplicate zone name: %s", zone.Name)
+		}
+		zoneNames[zone.Name] = true
+		
+		if (zone.Circle == nil) == (len(zone.Polygon) == 0) {
+			return fmt.Errorf("zone %s must define either a circle or a polygon", zone.Name)
+		}
+		if zone.Circle != nil && zone.Circle.Radius <= 0 {
+			return fmt.Errorf("zone %s must have a positive radius", zone.Name)
+		}
+		if zone.Circle == nil && len(zone.Polygon) < 3 {
+			return fmt.Errorf("zone %s polygon needs at least 3 points", zone.Name)
+		}
+		if zone.MinAltitude != nil && zone.MaxAltitude != nil && *zone.MinAltitude > *zone.MaxAltitude {
+			return fmt.Errorf("zone %s min altitude is above its max altitude", zone.Name)
+		}
+	}
+	
+	// Validate history window
+	if c.HistoryWindow < 1 {
+		return fmt.Errorf("history window must be at least 1 minute")
+	}
+	
+	// Validate mission idle time
+	if c.MissionIdle < 0 {
+		return fmt.Errorf("mission idle minutes must not be negative")
+	}
+	
+	// Validate patterns
+	patternNames := make(map[string]bool)
+	for i, pattern := range c.Patterns {
+		if pattern.Name == "" {
+			return fmt.Errorf("pattern %d has no name", i+1)
+		}
+		if patternNames[pattern.Name] {
+			return fmt.Errorf("duplicate pattern name: %s", pattern.Name)
+		}
+		patternNames[pattern.Name] = true
+		
+		if len(pattern.Steps) == 0 {
+			return fmt.Errorf("pattern %s needs at least one step", pattern.Name)
+		}
+		for j, step := range pattern.Steps {
+			if step.Event == "" {
+				return fmt.Errorf("pattern %s step %d has no event", pattern.Name, j+1)
+			}
+			if err := normalizeCoalition(&pattern.Steps[j].Coalition); err != nil {
+				return fmt.Errorf("pattern %s step %d: %v", pattern.Name, j+1, err)
+			}
+		}
+		if pattern.WithinSeconds < 0 || (len(pattern.Steps) > 1 && pattern.WithinSeconds == 0) {
+			return fmt.Errorf("pattern %s needs a positive within_seconds", pattern.Name)
+		}
+		if pattern.Absent != nil && (pattern.Absent.Event == "" || pattern.Absent.WithinSeconds <= 0) {
+			return fmt.Errorf("pattern %s absent needs an event and a positive within_seconds", pattern.Name)
+		}
+		if pattern.Absent != nil {
+			if err := normalizeCoalition(&pattern.Absent.Coalition); err != nil {
+				return fmt.Errorf("pattern %s absent: %v", pattern.Name, err)
+			}
+		}
+	}
+	
+	// Validate action schemas
+	schemaTypes := make(map[string]bool)
+	for i, schema := range c.ActionSchemas {
+		if schema.Type == "" {
+			return fmt.Errorf("action schema %d has no type", i+1)
+		}
+		if schemaTypes[schema.Type] {
+			return fmt.Errorf("duplicate action schema: %s", schema.Type)
+		}
+		schemaTypes[schema.Type] = true
+		
+		paramNames := make(map[string]bool)
+		for j, param := range schema.Params {
+			if param.Name == "" {
+				return fmt.Errorf("action schema %s parameter %d has no name", schema.Type, j+1)
+			}
+			if paramNames[param.Name] {
+				return fmt.Errorf("action schema %s has duplicate parameter %s", schema.Type, param.Name)
+			}
+			paramNames[param.Name] = true
+			switch param.Type {
+			case "string", "integer", "number", "boolean":
+			default:
+				return fmt.Errorf("action schema %s parameter %s has unknown type %q", schema.Type, param.Name, param.Type)
+			}
+			if len(param.Enum) > 0 && param.Type != "string" {
+				return fmt.Errorf("action schema %s parameter %s: enum is only allowed for strings", schema.Type, param.Name)
+			}
+		}
+	}
+	
+	// Validate action limits
+	limitNames := make(map[string]bool)
+	for i, limit := range c.ActionLimits {
+		if limit.Name == "" {
+			return fmt.Errorf("action limit %d has no name", i+1)
+		}
+		if limitNames[limit.Name] {
+			return fmt.Errorf("duplicate action limit name: %s", limit.Name)
+		}
+		limitNames[limit.Name] = true
+		
+		if limit.CooldownSeconds < 0 || limit.MaxActions < 0 || limit.MaxUnits < 0 {
+			return fmt.Errorf("action limit %s must not have negative values", limit.Name)
+		}
+		if limit.CooldownSeconds == 0 && limit.MaxActions == 0 && limit.MaxUnits == 0 {
+			return fmt.Errorf("action limit %s needs cooldown_seconds, max_actions or max_units", limit.Name)
+		}
+		for _, field := range limit.Per {
+			switch field {
+			case "rule", "action_type", "sub_type", "zone", "unit_type", "group_name":
+			default:
+				return fmt.Errorf("action limit %s has unknown per field %q", limit.Name, field)
+			}
+		}
+	}
+	if c.ActionDedup < 0 {
+		return fmt.Errorf("action dedup seconds must not be negative")
+	}
+	
 	// Validate log level
 	validLogLevels := map[string]bool{
 		"debug": true,
@@ -253,3 +643,17 @@ func validateConfig(c *Config) error {
 	
 	return nil
 }
+
+// normalizeCoalition replaces a coalition name from the configuration with the
+// one used in messages, e.g. "BLUE" or "2" with "blue". An empty name is kept.
+func normalizeCoalition(coalition *string) error {
+	if *coalition == "" {
+		return nil
+	}
+	parsed, err := models.ParseCoalition(*coalition)
+	if err != nil {
+		return err
+	}
+	*coalition = string(parsed)
+	return nil
+}
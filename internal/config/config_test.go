@@ -0,0 +1,118 @@
+// internal/config/config_test.go
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// validConfig returns the default configuration with a rules directory that exists
+func validConfig(t *testing.T) *Config {
+	t.Helper()
+	c := DefaultConfig()
+	c.RulesDirs = []string{t.TempDir()}
+	return c
+}
+
+func TestPatternCoalitionsAreNormalized(t *testing.T) {
+	tests := []struct {
+		step, absent string
+		want         string // Step and absent coalition after validation, or the error
+	}{
+		{"BLUE", "", "blue/"},
+		{"2", "Red", "blue/red"},
+		{"", "neutrals", "/neutral"},
+		{"purple", "", `pattern p step 1: unknown coalition "purple"`},
+		{"", "0x2", `pattern p absent: unknown coalition "0x2"`},
+	}
+	for _, tt := range tests {
+		c := validConfig(t)
+		c.Patterns = []PatternConfig{{
+			Name:   "p",
+			Steps:  []PatternStepConfig{{Event: "unit_detected", Coalition: tt.step}},
+			Absent: &PatternAbsentConfig{PatternStepConfig: PatternStepConfig{Event: "unit_destroyed", Coalition: tt.absent}, WithinSeconds: 30},
+		}}
+
+		var got string
+		if err := validateConfig(c); err != nil {
+			got = err.Error()
+		} else {
+			got = c.Patterns[0].Steps[0].Coalition + "/" + c.Patterns[0].Absent.Coalition
+		}
+		if !strings.HasPrefix(got, tt.want) {
+			t.Errorf("step %q, absent %q: got %q, want %q", tt.step, tt.absent, got, tt.want)
+		}
+	}
+}
+
+func TestZoneValidation(t *testing.T) {
+	low, high := 100.0, 50.0
+	circle := &CircleConfig{X: 0, Z: 0, Radius: 1000}
+	square := []PointConfig{{X: 0, Z: 0}, {X: 10, Z: 0}, {X: 10, Z: 10}, {X: 0, Z: 10}}
+
+	tests := []struct {
+		name  string
+		zones []ZoneConfig
+		want  string // Error, or "" for a valid configuration
+	}{
+		{"circle and polygon zones", []ZoneConfig{{Name: "ALPHA", Circle: circle}, {Name: "BRAVO", Polygon: square}}, ""},
+		{"no name", []ZoneConfig{{Circle: circle}}, "zone 1 has no name"},
+		{"duplicate name", []ZoneConfig{{Name: "ALPHA", Circle: circle}, {Name: "ALPHA", Polygon: square}}, "duplicate zone name: ALPHA"},
+		{"no shape", []ZoneConfig{{Name: "ALPHA"}}, "zone ALPHA must define either a circle or a polygon"},
+		{"both shapes", []ZoneConfig{{Name: "ALPHA", Circle: circle, Polygon: square}}, "zone ALPHA must define either a circle or a polygon"},
+		{"zero radius", []ZoneConfig{{Name: "ALPHA", Circle: &CircleConfig{}}}, "zone ALPHA must have a positive radius"},
+		{"two point polygon", []ZoneConfig{{Name: "BRAVO", Polygon: square[:2]}}, "zone BRAVO polygon needs at least 3 points"},
+		{"inverted altitude band", []ZoneConfig{{Name: "ALPHA", Circle: circle, MinAltitude: &low, MaxAltitude: &high}}, "zone ALPHA min altitude is above its max altitude"},
+	}
+	for _, tt := range tests {
+		c := validConfig(t)
+		c.Zones = tt.zones
+
+		var got string
+		if err := validateConfig(c); err != nil {
+			got = err.Error()
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestActionSchemaValidation(t *testing.T) {
+	param := func(name, typ string, enum ...string) ActionParamConfig {
+		return ActionParamConfig{Name: name, Type: typ, Enum: enum}
+	}
+
+	tests := []struct {
+		name    string
+		schemas []ActionSchemaConfig
+		want    string // Error, or "" for a valid configuration
+	}{
+		{"valid schemas", []ActionSchemaConfig{
+			{Type: "jammer", Params: []ActionParamConfig{param("enabled", "boolean"), param("mode", "string", "spot", "barrage")}},
+			{Type: "smoke", SubTypes: []string{"marker"}},
+		}, ""},
+		{"no type", []ActionSchemaConfig{{}}, "action schema 1 has no type"},
+		{"duplicate type", []ActionSchemaConfig{{Type: "jammer"}, {Type: "jammer"}}, "duplicate action schema: jammer"},
+		{"parameter without name", []ActionSchemaConfig{{Type: "jammer", Params: []ActionParamConfig{param("", "string")}}},
+			"action schema jammer parameter 1 has no name"},
+		{"duplicate parameter", []ActionSchemaConfig{{Type: "jammer", Params: []ActionParamConfig{param("mode", "string"), param("mode", "integer")}}},
+			"action schema jammer has duplicate parameter mode"},
+		{"unknown parameter type", []ActionSchemaConfig{{Type: "jammer", Params: []ActionParamConfig{param("power", "float")}}},
+			`action schema jammer parameter power has unknown type "float"`},
+		{"enum of numbers", []ActionSchemaConfig{{Type: "jammer", Params: []ActionParamConfig{param("power", "integer", "1", "2")}}},
+			"action schema jammer parameter power: enum is only allowed for strings"},
+	}
+	for _, tt := range tests {
+		c := validConfig(t)
+		c.ActionSchemas = tt.schemas
+
+		var got string
+		if err := validateConfig(c); err != nil {
+			got = err.Error()
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
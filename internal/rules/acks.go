@@ -0,0 +1,147 @@
+// internal/rules/acks.go
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/bass4/dcs-ice/pkg/models"
+)
+
+// actionSeq numbers the actions handed out across all missions
+var actionSeq uint64
+
+func nextActionID() string {
+	return fmt.Sprintf("act-%d", atomic.AddUint64(&actionSeq, 1))
+}
+
+// ErrUnknownAction is returned for an action ID the mission's action log does not hold
+var ErrUnknownAction = errors.New("unknown action")
+
+// track gives the actions of a result their IDs and records them in the
+// mission's action log, delayed ones as scheduled and the others as sent.
+// The actions a retry is for are marked retried.
+func (ms *missionState) track(result *EvaluationResult, now int64) {
+	actions := make([]models.Action, 0, len(result.Actions))
+	for i := range result.Trace {
+		firing := &result.Trace[i]
+		tracked := make([]models.Action, 0, len(firing.Actions))
+		for _, action := range firing.Actions {
+			action.ID = nextActionID()
+			status := models.ActionSent
+			if action.Delay > 0 {
+				status = models.ActionScheduled
+			}
+			ms.actions.Record(action, status, now)
+			if action.RetryOf != "" {
+				ms.actions.MarkRetried(action.RetryOf, action.ID, now)
+			}
+			tracked = append(tracked, action)
+		}
+		firing.Actions = tracked
+		actions = append(actions, tracked...)
+	}
+	result.Actions = actions
+}
+
+// due hands out the scheduled actions that came due and records them as sent
+func (ms *missionState) due() []ScheduledAction {
+	due := ms.queue.due()
+	if len(due) > 0 {
+		now := ms.queue.clock()
+		for _, scheduled := range due {
+			ms.actions.Update(scheduled.ID, models.ActionSent, "", "", now)
+		}
+	}
+	return due
+}
+
+// queueNow queues tracked actions due at once and records them as scheduled,
+// for actions that have no request to go back with
+func (ms *missionState) queueNow(actions []models.Action) {
+	now := ms.queue.clock()
+	for _, action := range actions {
+		ms.queue.add(&ScheduledAction{
+			ID:          action.ID,
+			MissionID:   ms.queue.missionID,
+			Action:      action,
+			Rule:        action.Rule,
+			ScheduledAt: now,
+			DueAt:       now,
+		})
+		ms.actions.Update(action.ID, models.ActionScheduled, "", "", now)
+	}
+}
+
+// ActionLog returns the actions handed out for a mission and what DCS
+// reported about them. An empty ID selects the default mission; an unknown
+// mission has an empty log.
+func (re *RuleEngine) ActionLog(missionID string) *models.ActionLog {
+	mission, ok := re.missions.lookup(missionID)
+	if !ok {
+		return models.NewActionLog()
+	}
+	return mission.actions
+}
+
+// AcknowledgeAction records what DCS reported about an action it was sent:
+// executed, or failed with a reason. groupName is the group DCS created or
+// addressed, if any. Actions that were not sent, or were acknowledged
+// already, are refused.
+func (re *RuleEngine) AcknowledgeAction(missionID, id string, status models.ActionStatus, reason, groupName string) (models.ActionRecord, error) {
+	mission, ok := re.missions.lookup(missionID)
+	if !ok {
+		return models.ActionRecord{}, fmt.Errorf("%w %q", ErrUnknownAction, id)
+	}
+	record, ok := mission.actions.Transition(id, models.ActionSent, status, reason, groupName, mission.queue.clock())
+	if !ok {
+		if record.ID == "" {
+			return record, fmt.Errorf("%w %q", ErrUnknownAction, id)
+		}
+		return record, fmt.Errorf("action %q is %s, only sent actions can be acknowledged", id, record.Status)
+	}
+
+	if status == models.ActionFailed {
+		fmt.Fprintf(re.out, "Action %s (%s from rule %s) failed: %s\n", id, record.Action.Type, record.Action.Rule, reason)
+	} else {
+		fmt.Fprintf(re.out, "Action %s (%s from rule %s) %s\n", id, record.Action.Type, record.Action.Rule, status)
+	}
+	return record, nil
+}
+
+// RetryAction sends a failed action of a mission again under a new ID and
+// marks it retried, so it is retried once. The retry bypasses the action
+// limits and is delivered like a scheduled action that is due at once.
+func (re *RuleEngine) RetryAction(missionID, id string) (ScheduledAction, error) {
+	mission, ok := re.missions.lookup(missionID)
+	if !ok {
+		return ScheduledAction{}, fmt.Errorf("%w %q", ErrUnknownAction, id)
+	}
+
+	now := mission.queue.clock()
+	retryID := nextActionID()
+	record, ok := mission.actions.MarkRetried(id, retryID, now)
+	if !ok {
+		if record.ID == "" {
+			return ScheduledAction{}, fmt.Errorf("%w %q", ErrUnknownAction, id)
+		}
+		return ScheduledAction{}, fmt.Errorf("action %q is %s, only failed actions can be retried", id, record.Status)
+	}
+
+	retry := record.Action.Retry(record.Action.Rule)
+	retry.ID = retryID
+	mission.actions.Record(retry, models.ActionScheduled, now)
+
+	scheduled := &ScheduledAction{
+		ID:          retry.ID,
+		MissionID:   mission.queue.missionID,
+		Action:      retry,
+		Rule:        retry.Rule,
+		ScheduledAt: now,
+		DueAt:       now,
+	}
+	mission.queue.add(scheduled)
+	fmt.Fprintf(re.out, "Retrying %s action %s as %s\n", retry.Type, id, retry.ID)
+	return *scheduled, nil
+}
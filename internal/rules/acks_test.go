@@ -0,0 +1,127 @@
+// internal/rules/acks_test.go
+package rules
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bass4/dcs-ice/pkg/models"
+)
+
+const ackRules = `
+rule Reinforce "Reinforce BRAVO and mark it later" {
+    when
+        Message.Event == "unit_destroyed"
+    then
+        Actions.AddReinforceAction("SAM_BRAVO", "BRAVO", "SA-6", 2);
+        Actions.AddSmokeAction("BRAVO", "green");
+        Actions.DelayLast(600);
+        Retract("Reinforce");
+}
+`
+
+func TestAcknowledgeAndRetry(t *testing.T) {
+	ruleEngine, _ := newTestEngine(t, ackRules)
+	result, err := ruleEngine.EvaluateMessage(testMessage("unit_destroyed", "BRAVO"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Actions) != 1 || len(result.Scheduled) != 1 {
+		t.Fatalf("got %d sent and %d scheduled actions, want 1 and 1", len(result.Actions), len(result.Scheduled))
+	}
+	sent, scheduled := result.Actions[0].ID, result.Scheduled[0].ID
+
+	acks := []struct {
+		name string
+		id   string
+		ok   bool
+	}{
+		{"ack of a sent action", sent, true},
+		{"second ack", sent, false},
+		{"ack of a scheduled action", scheduled, false},
+	}
+	for _, ack := range acks {
+		_, err := ruleEngine.AcknowledgeAction("", ack.id, models.ActionFailed, "zone not found", "")
+		if (err == nil) != ack.ok {
+			t.Errorf("%s: error %v", ack.name, err)
+		}
+	}
+
+	retry, err := ruleEngine.RetryAction("", sent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ruleEngine.RetryAction("", sent); err == nil {
+		t.Error("action was retried twice")
+	}
+	log := ruleEngine.ActionLog("")
+	if record, _ := log.Get(sent); record.Status != models.ActionRetried || record.RetriedBy != retry.ID {
+		t.Errorf("original is %s by %q, want retried by %s", record.Status, record.RetriedBy, retry.ID)
+	}
+	if log.CountFailed("reinforce") != 0 {
+		t.Errorf("CountFailed = %d after the retry, want 0", log.CountFailed("reinforce"))
+	}
+	if record, _ := log.Get(retry.ID); record.Attempt != 2 || record.Status != models.ActionScheduled {
+		t.Errorf("retry record is %+v", record)
+	}
+}
+
+func TestAcknowledgeUnknownMission(t *testing.T) {
+	ruleEngine, _ := newTestEngine(t, ackRules)
+
+	if _, err := ruleEngine.AcknowledgeAction("nowhere", "act-1", models.ActionExecuted, "", ""); !errors.Is(err, ErrUnknownAction) {
+		t.Errorf("ack: got %v, want ErrUnknownAction", err)
+	}
+	if _, err := ruleEngine.RetryAction("nowhere", "act-1"); !errors.Is(err, ErrUnknownAction) {
+		t.Errorf("retry: got %v, want ErrUnknownAction", err)
+	}
+	if _, ok := ruleEngine.CancelScheduledAction("nowhere", "act-1"); ok {
+		t.Error("cancelled an action of an unknown mission")
+	}
+	ruleEngine.ActionLog("nowhere")
+	ruleEngine.ScheduledActions("nowhere")
+	if _, ok := ruleEngine.missions.missions["nowhere"]; ok {
+		t.Error("requests for an unknown mission created it")
+	}
+}
+
+func TestRuleRetriesFailedActionOnce(t *testing.T) {
+	ruleEngine, _ := newTestEngine(t, ackRules+`
+rule RetryReinforce "Retry a failed reinforcement" salience 10 {
+    when
+        Message.Event == "action_failed"
+    then
+        Actions.RetryAction(Message.GetString("action_id"));
+        Retract("RetryReinforce");
+}
+
+rule RetryAgain "Retry the same action again" {
+    when
+        Message.Event == "action_failed"
+    then
+        Actions.RetryAction(Message.GetString("action_id"));
+        Retract("RetryAgain");
+}
+`)
+	result, err := ruleEngine.EvaluateMessage(testMessage("unit_destroyed", "BRAVO"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := result.Actions[0].ID
+	if _, err := ruleEngine.AcknowledgeAction("", sent, models.ActionFailed, "zone not found", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	failed := testMessage("action_failed", "BRAVO")
+	failed.Data = map[string]interface{}{"action_id": sent}
+	result, err = ruleEngine.EvaluateMessage(failed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Actions) != 1 || len(result.Rejected) != 1 {
+		t.Fatalf("got %d retries and %d rejected, want 1 and 1", len(result.Actions), len(result.Rejected))
+	}
+	if record, _ := ruleEngine.ActionLog("").Get(sent); record.Status != models.ActionRetried || record.RetriedBy != result.Actions[0].ID {
+		t.Errorf("original is %s by %q, want retried by %s", record.Status, record.RetriedBy, result.Actions[0].ID)
+	}
+}
@@ -0,0 +1,75 @@
+// internal/rules/actions.go
+package rules
+
+import (
+	"github.com/bass4/dcs-ice/internal/config"
+	"github.com/bass4/dcs-ice/pkg/models"
+)
+
+// RejectedAction is an action that was dropped because it does not match the
+// schema of its type
+type RejectedAction struct {
+	Action   models.Action `json:"action"`
+	Rule     string        `json:"rule"`
+	Problems []string      `json:"problems"`
+}
+
+// NewActionSchemaRegistry builds the schema registry from the built-in
+// schemas and the validated schema configuration, which may override them
+func NewActionSchemaRegistry(schemaConfigs []config.ActionSchemaConfig) *models.ActionSchemaRegistry {
+	schemas := models.DefaultActionSchemas()
+	for _, sc := range schemaConfigs {
+		schema := models.ActionSchema{Type: sc.Type, SubTypes: sc.SubTypes, Params: make([]models.ActionParam, 0, len(sc.Params))}
+		for _, pc := range sc.Params {
+			schema.Params = append(schema.Params, models.ActionParam{
+				Name:     pc.Name,
+				Type:     models.ParamType(pc.Type),
+				Required: pc.Required,
+				Enum:     pc.Enum,
+			})
+		}
+		schemas = append(schemas, schema)
+	}
+	return models.NewActionSchemaRegistry(schemas)
+}
+
+// ActionSchemas returns the schemas actions are validated against
+func (re *RuleEngine) ActionSchemas() *models.ActionSchemaRegistry {
+	return re.actionSchemas
+}
+
+// dropInvalid removes the actions the rule could not build or that do not
+// match their schema from the result and its trace and records them as rejected
+func (r *EvaluationResult) dropInvalid(schemas *models.ActionSchemaRegistry) {
+	// The trace shares the backing array of Actions, so filter into new slices
+	valid := make([]models.Action, 0, len(r.Actions))
+	for _, action := range r.Actions {
+		problems := actionProblems(schemas, action)
+		if len(problems) == 0 {
+			valid = append(valid, action)
+			continue
+		}
+		r.Rejected = append(r.Rejected, RejectedAction{Action: action, Rule: action.Rule, Problems: problems})
+	}
+	r.Actions = valid
+
+	if len(r.Rejected) == 0 {
+		return
+	}
+	for i := range r.Trace {
+		kept := make([]models.Action, 0, len(r.Trace[i].Actions))
+		for _, action := range r.Trace[i].Actions {
+			if len(actionProblems(schemas, action)) == 0 {
+				kept = append(kept, action)
+			}
+		}
+		r.Trace[i].Actions = kept
+	}
+}
+
+// actionProblems returns why an action cannot be sent: what the rule could not
+// build as asked, then where it does not match its schema
+func actionProblems(schemas *models.ActionSchemaRegistry, action models.Action) []string {
+	problems := append([]string(nil), action.Problems()...)
+	return append(problems, schemas.Validate(action)...)
+}
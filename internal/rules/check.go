@@ -0,0 +1,598 @@
+// internal/rules/check.go
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/hyperjumptech/grule-rule-engine/builder"
+	"github.com/hyperjumptech/grule-rule-engine/pkg"
+)
+
+// Diagnostic is a problem found in a rule file by the rule checker
+type Diagnostic struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	if d.Rule == "" {
+		return fmt.Sprintf("%s:%d:%d: %s", d.File, d.Line, d.Column, d.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s (rule %s)", d.File, d.Line, d.Column, d.Message, d.Rule)
+}
+
+// CheckRules compiles every rule file found in dirs and files and checks each
+// fact and member reference against the Go types registered in the data
+// context. It returns the diagnostics sorted by file and position.
+func CheckRules(dirs, files []string) ([]Diagnostic, error) {
+	paths, err := collectRuleFiles(dirs, files)
+	if err != nil {
+		return nil, err
+	}
+
+	var diagnostics []Diagnostic
+	declarations := make(ruleDeclarations)
+
+	for _, path := range paths {
+		source, err := os.ReadFile(path)
+		if err != nil {
+			diagnostics = append(diagnostics, Diagnostic{File: path, Line: 1, Column: 1, Message: err.Error()})
+			continue
+		}
+
+		diagnostics = append(diagnostics, compileDiagnostics(path, source)...)
+
+		checker := newGRLChecker(path, string(source))
+		diagnostics = append(diagnostics, checker.check()...)
+
+		_, contextDiagnostics := ruleContexts(path, string(source))
+		diagnostics = append(diagnostics, contextDiagnostics...)
+
+		diagnostics = append(diagnostics, declarations.declare(checker.rules)...)
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i], diagnostics[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+
+	return diagnostics, nil
+}
+
+// ruleDeclarations maps each rule name to the location it was first declared at
+type ruleDeclarations map[string]Diagnostic
+
+// declare records the rule declarations of a file and reports every rule whose
+// name was declared before. Rule names share one knowledge base, so they must
+// be unique across files.
+func (d ruleDeclarations) declare(rules []Diagnostic) []Diagnostic {
+	var duplicates []Diagnostic
+	for _, rule := range rules {
+		if first, exists := d[rule.Rule]; exists {
+			rule.Message = fmt.Sprintf("duplicate rule name %s, first declared at %s:%d", rule.Rule, first.File, first.Line)
+			duplicates = append(duplicates, rule)
+			continue
+		}
+		d[rule.Rule] = rule
+	}
+	return duplicates
+}
+
+// grlErrorPosition extracts the position from grule syntax errors ("grl error on 3:14 ...")
+var grlErrorPosition = regexp.MustCompile(`^grl error on (\d+):(\d+) (.*)$`)
+
+// compileDiagnostics compiles a single file on its own and reports its syntax errors
+func compileDiagnostics(path string, source []byte) []Diagnostic {
+	knowledgeLibrary := ast.NewKnowledgeLibrary()
+	err := builder.NewRuleBuilder(knowledgeLibrary).BuildRuleFromResource(KnowledgeBaseName, KnowledgeBaseVersion, pkg.NewBytesResource(source))
+	if err == nil {
+		return nil
+	}
+
+	var reporter *pkg.GruleErrorReporter
+	if !errors.As(err, &reporter) {
+		return []Diagnostic{{File: path, Line: 1, Column: 1, Message: err.Error()}}
+	}
+
+	var diagnostics []Diagnostic
+	for _, e := range reporter.Errors {
+		d := Diagnostic{File: path, Line: 1, Column: 1, Message: e.Error()}
+		if m := grlErrorPosition.FindStringSubmatch(e.Error()); m != nil {
+			d.Line, _ = strconv.Atoi(m[1])
+			d.Column, _ = strconv.Atoi(m[2])
+			d.Column++ // antlr columns are zero based
+			d.Message = "syntax error: " + m[3]
+		}
+		diagnostics = append(diagnostics, d)
+	}
+	return diagnostics
+}
+
+// Token kinds produced by the GRL scanner
+const (
+	tokIdent = iota
+	tokString
+	tokNumber
+	tokOperator
+	tokPunct
+)
+
+type grlToken struct {
+	kind   int
+	text   string
+	line   int
+	column int
+}
+
+// scanGRL splits GRL source into tokens, dropping whitespace and comments
+func scanGRL(source string) []grlToken {
+	var tokens []grlToken
+	line, column := 1, 1
+	i := 0
+
+	advance := func(n int) {
+		for k := 0; k < n && i < len(source); k++ {
+			if source[i] == '\n' {
+				line++
+				column = 1
+			} else {
+				column++
+			}
+			i++
+		}
+	}
+
+	for i < len(source) {
+		c := source[i]
+		startLine, startColumn, start := line, column, i
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			advance(1)
+
+		case strings.HasPrefix(source[i:], "//"):
+			for i < len(source) && source[i] != '\n' {
+				advance(1)
+			}
+
+		case strings.HasPrefix(source[i:], "/*"):
+			advance(2)
+			for i < len(source) && !strings.HasPrefix(source[i:], "*/") {
+				advance(1)
+			}
+			advance(2)
+
+		case c == '"' || c == '\'':
+			advance(1)
+			for i < len(source) && source[i] != c {
+				if source[i] == '\\' {
+					advance(1)
+				}
+				advance(1)
+			}
+			advance(1)
+			tokens = append(tokens, grlToken{tokString, source[start:i], startLine, startColumn})
+
+		case isIdentStart(c):
+			for i < len(source) && (isIdentStart(source[i]) || isDigit(source[i])) {
+				advance(1)
+			}
+			tokens = append(tokens, grlToken{tokIdent, source[start:i], startLine, startColumn})
+
+		case isDigit(c):
+			for i < len(source) && (isDigit(source[i]) || source[i] == '.' || isIdentStart(source[i])) {
+				advance(1)
+			}
+			tokens = append(tokens, grlToken{tokNumber, source[start:i], startLine, startColumn})
+
+		case strings.ContainsRune("=!<>&|", rune(c)):
+			advance(1)
+			if i < len(source) && strings.ContainsRune("=&|", rune(source[i])) {
+				advance(1)
+			}
+			tokens = append(tokens, grlToken{tokOperator, source[start:i], startLine, startColumn})
+
+		case strings.ContainsRune("+-*/%", rune(c)):
+			advance(1)
+			tokens = append(tokens, grlToken{tokOperator, source[start:i], startLine, startColumn})
+
+		default:
+			advance(1)
+			tokens = append(tokens, grlToken{tokPunct, source[start:i], startLine, startColumn})
+		}
+	}
+
+	return tokens
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// GRL keywords that can appear where an identifier is expected
+var grlKeywords = map[string]bool{
+	"rule": true, "salience": true, "when": true, "then": true,
+	"true": true, "false": true, "nil": true,
+}
+
+// comparisonOperators are the operators whose operands must have matching types
+var comparisonOperators = map[string]bool{
+	"==": true, "!=": true, "<": true, ">": true, "<=": true, ">=": true, "=": true,
+}
+
+// orderingOperators compare by order, which is lexical for strings
+var orderingOperators = map[string]bool{
+	"<": true, ">": true, "<=": true, ">=": true,
+}
+
+// valueKind is the coarse type of a GRL value used for type checking
+type valueKind int
+
+const (
+	kindUnknown valueKind = iota
+	kindString
+	kindNumber
+	kindBool
+)
+
+func (k valueKind) String() string {
+	switch k {
+	case kindString:
+		return "string"
+	case kindNumber:
+		return "number"
+	case kindBool:
+		return "bool"
+	}
+	return "unknown"
+}
+
+// kindOf maps a Go type to the GRL value kind
+func kindOf(t reflect.Type) valueKind {
+	switch t.Kind() {
+	case reflect.String:
+		return kindString
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return kindNumber
+	case reflect.Bool:
+		return kindBool
+	}
+	return kindUnknown
+}
+
+// literalKind returns the kind of a literal token, or kindUnknown if it is not a literal
+func literalKind(tok grlToken) valueKind {
+	switch {
+	case tok.kind == tokString:
+		return kindString
+	case tok.kind == tokNumber:
+		return kindNumber
+	case tok.kind == tokIdent && (tok.text == "true" || tok.text == "false"):
+		return kindBool
+	}
+	return kindUnknown
+}
+
+// grlChecker validates the fact references of a single rule file
+type grlChecker struct {
+	path        string
+	tokens      []grlToken
+	diagnostics []Diagnostic
+	rules       []Diagnostic // location of every rule declaration
+	rule        string       // rule currently being checked
+	builtins    reflect.Type
+}
+
+func newGRLChecker(path, source string) *grlChecker {
+	return &grlChecker{
+		path:     path,
+		tokens:   scanGRL(source),
+		builtins: reflect.TypeOf(&ast.BuiltInFunctions{}),
+	}
+}
+
+// report records a diagnostic at the position of tok
+func (c *grlChecker) report(tok grlToken, format string, args ...interface{}) {
+	c.diagnostics = append(c.diagnostics, Diagnostic{
+		File:    c.path,
+		Line:    tok.line,
+		Column:  tok.column,
+		Rule:    c.rule,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// check walks the tokens and validates every reference expression
+func (c *grlChecker) check() []Diagnostic {
+	for i := 0; i < len(c.tokens); i++ {
+		tok := c.tokens[i]
+
+		if tok.kind != tokIdent {
+			continue
+		}
+
+		if tok.text == "rule" && i+1 < len(c.tokens) && c.tokens[i+1].kind == tokIdent {
+			c.rule = c.tokens[i+1].text
+			c.rules = append(c.rules, Diagnostic{File: c.path, Line: tok.line, Column: tok.column, Rule: c.rule})
+			i++
+			continue
+		}
+
+		if grlKeywords[tok.text] || c.isMember(i) {
+			continue
+		}
+
+		i = c.checkReference(i)
+	}
+
+	return c.diagnostics
+}
+
+// isMember reports whether the identifier at i follows a dot
+func (c *grlChecker) isMember(i int) bool {
+	return i > 0 && c.tokens[i-1].text == "."
+}
+
+// checkReference checks the reference expression starting at token i, such as
+// Message.Zone or Messages.CountMessagesByEvent("x"), and returns the index of
+// its last token
+func (c *grlChecker) checkReference(i int) int {
+	head := c.tokens[i]
+	end := i
+
+	// A bare function call must be a grule built-in
+	if c.next(i, "(") {
+		if _, ok := c.builtins.MethodByName(head.text); !ok {
+			c.report(head, "unknown function %s", head.text)
+		}
+		return i
+	}
+
+	factType, ok := factTypes[head.text]
+	if !ok {
+		c.report(head, "unknown fact %s", head.text)
+		return c.skipChain(i)
+	}
+
+	current := factType
+	kind := kindUnknown
+
+	for c.next(end, ".") && end+2 < len(c.tokens) && c.tokens[end+2].kind == tokIdent {
+		member := c.tokens[end+2]
+		end += 2
+
+		if current == nil {
+			// The type is no longer known (e.g. interface{} or a string function), stop checking
+			kind = kindUnknown
+			continue
+		}
+
+		if c.next(end, "(") {
+			closing, args := c.arguments(end + 1)
+			current, kind = c.checkMethod(current, member, args)
+			end = closing
+		} else {
+			current, kind = c.checkField(current, member)
+		}
+	}
+
+	c.checkComparison(i, end, kind)
+	return end
+}
+
+// next reports whether the token after i has the given text
+func (c *grlChecker) next(i int, text string) bool {
+	return i+1 < len(c.tokens) && c.tokens[i+1].text == text
+}
+
+// skipChain returns the index of the last token of a dotted chain starting at i
+func (c *grlChecker) skipChain(i int) int {
+	for c.next(i, ".") && i+2 < len(c.tokens) {
+		i += 2
+	}
+	return i
+}
+
+// arguments returns the index of the parenthesis closing the one at open and
+// the tokens of each top-level argument
+func (c *grlChecker) arguments(open int) (int, [][]grlToken) {
+	var args [][]grlToken
+	var current []grlToken
+	depth := 0
+
+	for j := open; j < len(c.tokens); j++ {
+		tok := c.tokens[j]
+		switch {
+		case tok.text == "(":
+			depth++
+			if depth == 1 {
+				continue
+			}
+		case tok.text == ")":
+			depth--
+			if depth == 0 {
+				if len(current) > 0 || len(args) > 0 {
+					args = append(args, current)
+				}
+				return j, args
+			}
+		case tok.text == "," && depth == 1:
+			args = append(args, current)
+			current = nil
+			continue
+		}
+		current = append(current, tok)
+	}
+
+	return len(c.tokens) - 1, args
+}
+
+// checkField checks a field access and returns the field's type and kind
+func (c *grlChecker) checkField(t reflect.Type, member grlToken) (reflect.Type, valueKind) {
+	structType := t
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+
+	if structType.Kind() == reflect.Struct {
+		if field, ok := structType.FieldByName(member.text); ok && field.PkgPath == "" {
+			return typeForChain(field.Type), kindOf(field.Type)
+		}
+	}
+
+	if _, ok := t.MethodByName(member.text); ok {
+		c.report(member, "%s is a method of %s and must be called as %s(...)", member.text, typeName(t), member.text)
+		return nil, kindUnknown
+	}
+
+	c.report(member, "%s has no field %s", typeName(t), member.text)
+	return nil, kindUnknown
+}
+
+// checkMethod checks a method call and returns the type and kind of its result
+func (c *grlChecker) checkMethod(t reflect.Type, member grlToken, args [][]grlToken) (reflect.Type, valueKind) {
+	method, ok := t.MethodByName(member.text)
+	if !ok {
+		if kindOf(t) == kindString {
+			// grule provides string functions such as Len and ToUpper on string values
+			return nil, kindUnknown
+		}
+		if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct {
+			if _, isField := t.Elem().FieldByName(member.text); isField {
+				c.report(member, "%s is a field of %s, not a method", member.text, typeName(t))
+				return nil, kindUnknown
+			}
+		}
+		c.report(member, "%s has no method %s", typeName(t), member.text)
+		return nil, kindUnknown
+	}
+
+	// The receiver is the first parameter of a method obtained from a type
+	params := method.Type.NumIn() - 1
+	variadic := method.Type.IsVariadic()
+
+	if (!variadic && len(args) != params) || (variadic && len(args) < params-1) {
+		c.report(member, "%s.%s expects %d argument(s), got %d", typeName(t), member.text, params, len(args))
+	} else {
+		for a, arg := range args {
+			p := a + 1
+			if p > params {
+				p = params
+			}
+			paramType := method.Type.In(p)
+			if variadic && a+1 >= params {
+				paramType = paramType.Elem()
+			}
+			if len(arg) != 1 {
+				continue
+			}
+			expected, actual := kindOf(paramType), literalKind(arg[0])
+			if expected != kindUnknown && actual != kindUnknown && expected != actual {
+				c.report(arg[0], "argument %d of %s.%s must be a %s, got %s %s", a+1, typeName(t), member.text, expected, actual, arg[0].text)
+				continue
+			}
+			// grule passes number literals without converting them to the parameter type
+			if actual == kindNumber && expected == kindNumber {
+				if literal := numberLiteralKind(arg[0]); literal != paramType.Kind() {
+					if paramType.Kind() == reflect.Float64 {
+						c.report(arg[0], "argument %d of %s.%s is a float64, write %s as %s.0", a+1, typeName(t), member.text, arg[0].text, arg[0].text)
+					} else {
+						c.report(arg[0], "argument %d of %s.%s is a %s, which a GRL %s literal cannot be passed as", a+1, typeName(t), member.text, paramType.Kind(), literal)
+					}
+				}
+			}
+		}
+	}
+
+	if method.Type.NumOut() == 0 {
+		return nil, kindUnknown
+	}
+	result := method.Type.Out(0)
+	return typeForChain(result), kindOf(result)
+}
+
+// numberLiteralKind returns the Go kind grule gives a number literal:
+// float64 for decimals and int64 otherwise
+func numberLiteralKind(tok grlToken) reflect.Kind {
+	if strings.ContainsAny(tok.text, ".eE") && !strings.HasPrefix(tok.text, "0x") {
+		return reflect.Float64
+	}
+	return reflect.Int64
+}
+
+// checkComparison checks a comparison between the reference spanning tokens
+// start..end and a literal on either side of it
+func (c *grlChecker) checkComparison(start, end int, kind valueKind) {
+	if kind == kindUnknown {
+		return
+	}
+
+	// Reference on the left: Message.Count > "3"
+	if end+2 < len(c.tokens) && comparisonOperators[c.tokens[end+1].text] {
+		c.checkOperands(c.tokens[end+1], kind, c.tokens[end+2])
+	}
+
+	// Reference on the right: "3" < Message.Count
+	if start >= 2 && comparisonOperators[c.tokens[start-1].text] {
+		c.checkOperands(c.tokens[start-1], kind, c.tokens[start-2])
+	}
+}
+
+// checkOperands reports a literal whose kind does not match the referenced value
+func (c *grlChecker) checkOperands(operator grlToken, kind valueKind, literal grlToken) {
+	litKind := literalKind(literal)
+	if litKind == kindUnknown {
+		return
+	}
+
+	if litKind != kind {
+		c.report(literal, "type mismatch: %s value compared with %s %s", kind, litKind, literal.text)
+		return
+	}
+
+	if kind == kindString && orderingOperators[operator.text] {
+		if _, err := strconv.ParseFloat(strings.Trim(literal.text, `"'`), 64); err == nil {
+			c.report(operator, "%s compares strings lexically; %s looks like a number", operator.text, literal.text)
+		}
+	}
+}
+
+// typeForChain returns the type to resolve further members against, or nil if
+// members of this type cannot be checked
+func typeForChain(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Interface {
+		return nil
+	}
+	return t
+}
+
+// typeName returns a short name for a fact type, e.g. models.Message
+func typeName(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.String()
+}
@@ -0,0 +1,122 @@
+// internal/rules/check_test.go
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string // File name to GRL source
+		want  []string          // Expected diagnostics, by substring
+	}{
+		{
+			name: "valid rule",
+			files: map[string]string{"a.grl": `
+rule Valid "valid" {
+    when Message.Event == "unit_detected" && Message.Count > 3
+    then Actions.AddAlertAction("alert", "red", "Enemy"); Retract("Valid");
+}`},
+		},
+		{
+			name: "unknown member",
+			files: map[string]string{"a.grl": `
+rule Unknown "unknown" {
+    when Messages.HasMessageInZone("ALPHA")
+    then Retract("Unknown");
+}`},
+			want: []string{"a.grl:3:19: models.MessageCollection has no method HasMessageInZone (rule Unknown)"},
+		},
+		{
+			name: "wrong arity",
+			files: map[string]string{"a.grl": `
+rule Arity "arity" {
+    when Message.Event == "unit_detected"
+    then Actions.AddAlertAction("alert", "red"); Retract("Arity");
+}`},
+			want: []string{"models.ActionCollector.AddAlertAction expects 3 argument(s), got 2 (rule Arity)"},
+		},
+		{
+			name: "string compared with int field",
+			files: map[string]string{"a.grl": `
+rule Mismatch "mismatch" {
+    when Message.Count > "3"
+    then Retract("Mismatch");
+}`},
+			want: []string{`type mismatch: number value compared with string "3" (rule Mismatch)`},
+		},
+		{
+			name: "int literal for float64 parameter",
+			files: map[string]string{"a.grl": `
+rule Flare "flare" {
+    when Message.Event == "unit_detected"
+    then Actions.AddIlluminationAction("ALPHA", 500); Retract("Flare");
+}`},
+			want: []string{"argument 2 of models.ActionCollector.AddIlluminationAction is a float64, write 500 as 500.0 (rule Flare)"},
+		},
+		{
+			name: "duplicate rule names in one file",
+			files: map[string]string{"a.grl": `
+rule Twice "first" { when true then Retract("Twice"); }
+rule Twice "second" { when true then Retract("Twice"); }`},
+			want: []string{
+				"duplicate rule entry Twice",
+				"a.grl:3:1: duplicate rule name Twice, first declared at",
+			},
+		},
+		{
+			name: "duplicate rule names across files",
+			files: map[string]string{
+				"a.grl": `rule Shared "a" { when true then Retract("Shared"); }`,
+				"b.grl": `rule Shared "b" { when true then Retract("Shared"); }`,
+			},
+			want: []string{"b.grl:1:1: duplicate rule name Shared, first declared at"},
+		},
+		{
+			name: "when and then in comments and strings",
+			files: map[string]string{"a.grl": `
+// when Message.Nope then Actions.Nope()
+rule Keywords "fires when seen, then alerts" {
+    when Message.Event == "when" && Message.Zone != "then"
+    then Actions.AddAlertAction("alert", "red", "when then"); Retract("Keywords");
+}`},
+		},
+		{
+			name: "when and then in strings before an unknown member",
+			files: map[string]string{"a.grl": `
+rule Keywords "fires when seen, then alerts" {
+    when Message.Event == "when then" && Message.Nope == "x"
+    then Retract("Keywords");
+}`},
+			want: []string{"a.grl:3:50: models.Message has no field Nope (rule Keywords)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, grl := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(grl), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			diagnostics, err := CheckRules([]string{dir}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(diagnostics) != len(tt.want) {
+				t.Fatalf("got %d diagnostics %v, want %d", len(diagnostics), diagnostics, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if got := diagnostics[i].String(); !strings.Contains(got, want) {
+					t.Errorf("diagnostic %d is %q, want it to contain %q", i, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// internal/rules/context.go
+package rules
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// EvaluationContext is the kind of evaluation a rule is written for. Each
+// context puts different facts in the data context and runs its own knowledge
+// base holding only the rules that apply to it.
+type EvaluationContext string
+
+const (
+	ContextSingle EvaluationContext = "single" // One event, exposed as Message
+	ContextBatch  EvaluationContext = "batch"  // Several events, exposed as Messages
+)
+
+// evaluationContexts lists every context in a stable order
+var evaluationContexts = []EvaluationContext{ContextSingle, ContextBatch}
+
+// contextFacts lists the facts each context adds to the data context
+var contextFacts = map[EvaluationContext]map[string]bool{
+	ContextSingle: {"Message": true, "Actions": true, "World": true, "History": true, "Facts": true, "ActionLog": true},
+	ContextBatch:  {"Messages": true, "Actions": true, "World": true, "History": true, "Facts": true, "ActionLog": true},
+}
+
+// contextHeader matches the "// @context: batch" header of a rule file
+var contextHeader = regexp.MustCompile(`^//\s*@context:\s*(\S*)`)
+
+// parseContext validates a context name from a header or directory
+func parseContext(name string) (EvaluationContext, bool) {
+	for _, ctx := range evaluationContexts {
+		if string(ctx) == name {
+			return ctx, true
+		}
+	}
+	return "", false
+}
+
+// declaredContext returns the context a rule file declares, either through a
+// "// @context: single|batch" line in its leading comments or by living in a
+// directory named single or batch. The header wins over the directory. An
+// empty context means the file is undeclared.
+func declaredContext(path, source string) (EvaluationContext, error) {
+	for _, line := range strings.Split(source, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "//") {
+			break
+		}
+		if m := contextHeader.FindStringSubmatch(line); m != nil {
+			ctx, ok := parseContext(m[1])
+			if !ok {
+				return "", fmt.Errorf("unknown @context %q, expected %s or %s", m[1], ContextSingle, ContextBatch)
+			}
+			return ctx, nil
+		}
+	}
+
+	ctx, _ := parseContext(filepath.Base(filepath.Dir(path)))
+	return ctx, nil
+}
+
+// ruleFacts records the facts referenced by one rule
+type ruleFacts struct {
+	Diagnostic                     // Location of the rule declaration
+	facts      map[string]grlToken // First reference of each fact
+}
+
+// scanRuleFacts returns the facts referenced by each rule in source
+func scanRuleFacts(path, source string) []*ruleFacts {
+	tokens := scanGRL(source)
+
+	var rules []*ruleFacts
+	var current *ruleFacts
+	for i, tok := range tokens {
+		if tok.kind != tokIdent || (i > 0 && tokens[i-1].text == ".") {
+			continue
+		}
+
+		if tok.text == "rule" && i+1 < len(tokens) && tokens[i+1].kind == tokIdent {
+			current = &ruleFacts{
+				Diagnostic: Diagnostic{File: path, Line: tok.line, Column: tok.column, Rule: tokens[i+1].text},
+				facts:      make(map[string]grlToken),
+			}
+			rules = append(rules, current)
+			continue
+		}
+
+		if _, isFact := factTypes[tok.text]; isFact && current != nil {
+			if _, seen := current.facts[tok.text]; !seen {
+				current.facts[tok.text] = tok
+			}
+		}
+	}
+
+	return rules
+}
+
+// ruleContexts decides which contexts each rule in a file runs in. Rules in a
+// declared file run only in that context and may only reference its facts.
+// Rules in an undeclared file run in every context that provides all the facts
+// they reference, so a rule using only Actions runs everywhere. A rule that no
+// context can satisfy is reported as a diagnostic.
+func ruleContexts(path, source string) (map[string][]EvaluationContext, []Diagnostic) {
+	declared, err := declaredContext(path, source)
+	if err != nil {
+		return nil, []Diagnostic{{File: path, Line: 1, Column: 1, Message: err.Error()}}
+	}
+
+	contexts := make(map[string][]EvaluationContext)
+	var diagnostics []Diagnostic
+
+	for _, rule := range scanRuleFacts(path, source) {
+		if declared != "" {
+			for _, fact := range sortedFacts(rule.facts) {
+				if !contextFacts[declared][fact] {
+					tok := rule.facts[fact]
+					diagnostics = append(diagnostics, Diagnostic{
+						File:    path,
+						Line:    tok.line,
+						Column:  tok.column,
+						Rule:    rule.Rule,
+						Message: fmt.Sprintf("%s is not available to %s rules", fact, declared),
+					})
+				}
+			}
+			contexts[rule.Rule] = []EvaluationContext{declared}
+			continue
+		}
+
+		var matching []EvaluationContext
+		for _, ctx := range evaluationContexts {
+			if providesAll(ctx, rule.facts) {
+				matching = append(matching, ctx)
+			}
+		}
+		if len(matching) == 0 {
+			d := rule.Diagnostic
+			d.Message = fmt.Sprintf("rule references %s, which no evaluation context provides together", strings.Join(sortedFacts(rule.facts), ", "))
+			diagnostics = append(diagnostics, d)
+		}
+		contexts[rule.Rule] = matching
+	}
+
+	return contexts, diagnostics
+}
+
+// providesAll reports whether ctx provides every referenced fact
+func providesAll(ctx EvaluationContext, facts map[string]grlToken) bool {
+	for fact := range facts {
+		if !contextFacts[ctx][fact] {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedFacts(facts map[string]grlToken) []string {
+	names := make([]string, 0, len(facts))
+	for name := range facts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
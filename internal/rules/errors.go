@@ -0,0 +1,184 @@
+// internal/rules/errors.go
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// CycleExhaustedError is returned when rules were still eligible to fire after
+// the configured maximum number of cycles. Rule names the rule that kept firing.
+// The actions collected up to that point are still returned.
+type CycleExhaustedError struct {
+	Rule      string
+	MaxCycles uint64
+}
+
+func (e *CycleExhaustedError) Error() string {
+	if e.Rule == "" {
+		return fmt.Sprintf("rules still eligible after %d cycles", e.MaxCycles)
+	}
+	return fmt.Sprintf("rule %s still eligible after %d cycles; it should retract itself or change the facts it matches on", e.Rule, e.MaxCycles)
+}
+
+// RuleRuntimeError is returned when a rule fails while it is being executed.
+// The actions collected before the failure are still returned.
+type RuleRuntimeError struct {
+	Rule string
+	Err  error
+}
+
+func (e *RuleRuntimeError) Error() string {
+	if e.Rule == "" {
+		return fmt.Sprintf("rule execution failed: %v", e.Err)
+	}
+	return fmt.Sprintf("rule %s failed: %v", e.Rule, e.Err)
+}
+
+func (e *RuleRuntimeError) Unwrap() error {
+	return e.Err
+}
+
+// DataContextError is returned when a fact cannot be added to the data context.
+// No rules are evaluated in that case.
+type DataContextError struct {
+	Key string
+	Err error
+}
+
+func (e *DataContextError) Error() string {
+	return fmt.Sprintf("failed to add %s to data context: %v", e.Key, e.Err)
+}
+
+func (e *DataContextError) Unwrap() error {
+	return e.Err
+}
+
+// RuleConditionError is returned when a rule's when scope cannot be evaluated
+// against the facts, e.g. because it calls a method they do not have. The
+// evaluation stops there; the actions of rules that fired before are still returned.
+type RuleConditionError struct {
+	Rule string
+	Err  error
+}
+
+func (e *RuleConditionError) Error() string {
+	return fmt.Sprintf("rule %s condition failed: %v", e.Rule, e.Err)
+}
+
+func (e *RuleConditionError) Unwrap() error {
+	return e.Err
+}
+
+// EvaluationErrors holds the errors of a message and of the messages derived
+// from it, in evaluation order. errors.As finds the first error of a type.
+type EvaluationErrors []error
+
+func (e EvaluationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e EvaluationErrors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e EvaluationErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// joinErrors combines two errors, either of which may be nil
+func joinErrors(err, next error) error {
+	if next == nil {
+		return err
+	}
+	if err == nil {
+		return next
+	}
+	if joined, ok := err.(EvaluationErrors); ok {
+		return append(joined, next)
+	}
+	return EvaluationErrors{err, next}
+}
+
+// classifyExecuteError turns an error returned by the grule engine into one of
+// the typed errors above, using what the trace saw of the last cycle
+func classifyExecuteError(err error, trace *traceListener, kb *ast.KnowledgeBase, dataContext ast.IDataContext, maxCycles uint64) error {
+	if err == nil {
+		return nil
+	}
+
+	// grule notifies the listener before executing a rule, so the last firing is the failing one
+	if trace.executing {
+		return &RuleRuntimeError{Rule: trace.lastRule(), Err: err}
+	}
+
+	// A when scope that failed was never reported as evaluated
+	if rule, condErr := failingCondition(kb, dataContext, trace.evaluated); rule != "" {
+		return &RuleConditionError{Rule: rule, Err: condErr}
+	}
+
+	// Every rule was evaluated in a cycle past the limit and some were still eligible
+	if trace.began > maxCycles && len(trace.candidates) > 0 {
+		return &CycleExhaustedError{Rule: trace.nextRule(), MaxCycles: maxCycles}
+	}
+
+	return &RuleRuntimeError{Err: err}
+}
+
+// failingCondition evaluates the when scopes of the active rules that were not
+// evaluated in the last cycle and returns the first one that fails, by name.
+// grule's own error names the last executed rule instead and may be lost to a
+// recovered panic, so the condition is evaluated again to report it.
+func failingCondition(kb *ast.KnowledgeBase, dataContext ast.IDataContext, evaluated map[string]bool) (string, error) {
+	names := make([]string, 0, len(kb.RuleEntries))
+	for name, entry := range kb.RuleEntries {
+		if !entry.Retracted && !entry.Deleted && !evaluated[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := evaluateCondition(kb.RuleEntries[name], dataContext, kb.WorkingMemory); err != nil {
+			return name, err
+		}
+	}
+	return "", nil
+}
+
+// evaluateCondition evaluates a rule's when scope and reports why it failed
+func evaluateCondition(entry *ast.RuleEntry, dataContext ast.IDataContext, memory *ast.WorkingMemory) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	value, err := entry.WhenScope.Evaluate(dataContext, memory)
+	if err != nil {
+		return err
+	}
+	if value.Kind() != reflect.Bool {
+		return fmt.Errorf("when is not a boolean expression: %s", entry.WhenScope.Expression.GetGrlText())
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+// internal/rules/facts.go
+package rules
+
+import (
+	"reflect"
+
+	"github.com/bass4/dcs-ice/pkg/models"
+)
+
+// factTypes maps the names under which facts are added to the data context to
+// their Go types. Rules can only refer to these names; the rule checker uses
+// this table to validate member references.
+var factTypes = map[string]reflect.Type{
+	"Message":   reflect.TypeOf(&models.Message{}),
+	"Messages":  reflect.TypeOf(&models.MessageCollection{}),
+	"Actions":   reflect.TypeOf(&models.ActionCollector{}),
+	"World":     reflect.TypeOf(&models.WorldState{}),
+	"History":   reflect.TypeOf(&models.MessageHistory{}),
+	"Facts":     reflect.TypeOf(&models.FactStore{}),
+	"ActionLog": reflect.TypeOf(&models.ActionLog{}),
+}
@@ -0,0 +1,230 @@
+// internal/rules/limits.go
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bass4/dcs-ice/internal/config"
+	"github.com/bass4/dcs-ice/pkg/models"
+)
+
+// maxRecentActions bounds the actions remembered for deduplication per mission
+// before the ones outside the window are pruned
+const maxRecentActions = 1000
+
+// SuppressedAction is a valid action that was not sent because of a cooldown,
+// a budget or deduplication
+type SuppressedAction struct {
+	Action models.Action `json:"action"`
+	Rule   string        `json:"rule"`
+	Reason string        `json:"reason"`
+}
+
+// actionLimit is a compiled action limit
+type actionLimit struct {
+	name       string
+	filters    map[string]string // Action fields and the values they must have
+	per        []string
+	cooldown   int64
+	maxActions int
+	maxUnits   int
+}
+
+// compileActionLimits converts the validated action limit configuration
+func compileActionLimits(limitConfigs []config.ActionLimitConfig) []*actionLimit {
+	limits := make([]*actionLimit, 0, len(limitConfigs))
+	for _, lc := range limitConfigs {
+		limit := &actionLimit{
+			name:       lc.Name,
+			filters:    make(map[string]string),
+			per:        lc.Per,
+			cooldown:   lc.CooldownSeconds,
+			maxActions: lc.MaxActions,
+			maxUnits:   lc.MaxUnits,
+		}
+		for field, value := range map[string]string{
+			"rule":        lc.Rule,
+			"action_type": lc.ActionType,
+			"sub_type":    lc.SubType,
+			"zone":        lc.Zone,
+			"unit_type":   lc.UnitType,
+		} {
+			if value != "" {
+				limit.filters[field] = value
+			}
+		}
+		limits = append(limits, limit)
+	}
+	return limits
+}
+
+// actionField returns the value of an action field a limit can filter or
+// group by: rule, action_type, sub_type or a key of the action's data
+func actionField(action models.Action, data map[string]interface{}, field string) string {
+	switch field {
+	case "rule":
+		return action.Rule
+	case "action_type":
+		return action.Type
+	case "sub_type":
+		return action.SubType
+	}
+	if value, ok := data[field].(string); ok {
+		return value
+	}
+	return ""
+}
+
+// actionUnits returns the number of units an action involves: its count, or 1
+func actionUnits(data map[string]interface{}) int {
+	switch v := data["count"].(type) {
+	case string:
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			return n
+		}
+	case int64:
+		return int(v)
+	case float64:
+		return int(math.Round(v))
+	}
+	return 1
+}
+
+// actionLimiter enforces the action limits and deduplication of one mission.
+// Time is mission time in seconds, read from the mission's action queue clock
+// so that cooldowns run out between timestamped events.
+type actionLimiter struct {
+	mu       sync.Mutex
+	limits   []*actionLimit
+	dedup    int64
+	lastSent map[string]int64 // Limit counter key -> time of the last action let through
+	actions  map[string]int   // Limit counter key -> actions let through
+	units    map[string]int   // Limit counter key -> units of the actions let through
+	recent   map[string]int64 // Action identity -> time it was last sent
+}
+
+func newActionLimiter(limits []*actionLimit, dedup int64) *actionLimiter {
+	return &actionLimiter{
+		limits:   limits,
+		dedup:    dedup,
+		lastSent: make(map[string]int64),
+		actions:  make(map[string]int),
+		units:    make(map[string]int),
+		recent:   make(map[string]int64),
+	}
+}
+
+// apply removes the actions that exceed a limit or repeat a recent action
+// from the result and records them as suppressed, both on the result and on
+// the rule firing that produced them
+func (al *actionLimiter) apply(result *EvaluationResult, now int64) {
+	if len(al.limits) == 0 && al.dedup == 0 {
+		return
+	}
+
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	// The trace partitions the actions by firing, so the result's actions are rebuilt from it
+	actions := make([]models.Action, 0, len(result.Actions))
+	for i := range result.Trace {
+		firing := &result.Trace[i]
+		kept := make([]models.Action, 0, len(firing.Actions))
+		for _, action := range firing.Actions {
+			reason := al.allow(action, now)
+			if reason == "" {
+				kept = append(kept, action)
+				continue
+			}
+			suppressed := SuppressedAction{Action: action, Rule: action.Rule, Reason: reason}
+			firing.Suppressed = append(firing.Suppressed, suppressed)
+			result.Suppressed = append(result.Suppressed, suppressed)
+		}
+		firing.Actions = kept
+		actions = append(actions, kept...)
+	}
+	result.Actions = actions
+}
+
+// allow decides whether an action may be sent at mission time now and, if
+// so, counts it. It returns why the action is suppressed, or "".
+func (al *actionLimiter) allow(action models.Action, now int64) string {
+	data := action.Data()
+
+	var identity string
+	if al.dedup > 0 {
+		encoded, _ := json.Marshal(data)
+		identity = action.Type + "\x00" + action.SubType + "\x00" + string(encoded)
+		if sent, ok := al.recent[identity]; ok && elapsed(sent, now) < al.dedup {
+			return fmt.Sprintf("duplicate of an action sent %ds ago", elapsed(sent, now))
+		}
+	}
+
+	units := actionUnits(data)
+	var keys []string
+	for _, limit := range al.limits {
+		if !limit.matches(action, data) {
+			continue
+		}
+		key := limit.key(action, data)
+		if sent, ok := al.lastSent[key]; ok && limit.cooldown > 0 && elapsed(sent, now) < limit.cooldown {
+			return fmt.Sprintf("limit %s: cooldown, %ds left", limit.name, limit.cooldown-elapsed(sent, now))
+		}
+		if limit.maxActions > 0 && al.actions[key] >= limit.maxActions {
+			return fmt.Sprintf("limit %s: budget of %d actions used", limit.name, limit.maxActions)
+		}
+		if limit.maxUnits > 0 && al.units[key]+units > limit.maxUnits {
+			return fmt.Sprintf("limit %s: budget of %d units would be exceeded, %d used", limit.name, limit.maxUnits, al.units[key])
+		}
+		keys = append(keys, key)
+	}
+
+	for _, key := range keys {
+		al.lastSent[key] = now
+		al.actions[key]++
+		al.units[key] += units
+	}
+	if al.dedup > 0 {
+		al.recent[identity] = now
+		if len(al.recent) > maxRecentActions {
+			for id, sent := range al.recent {
+				if elapsed(sent, now) >= al.dedup {
+					delete(al.recent, id)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// elapsed returns the seconds from then to now, or 0 if now is earlier
+func elapsed(then, now int64) int64 {
+	if now < then {
+		return 0
+	}
+	return now - then
+}
+
+// matches reports whether an action passes all of the limit's filters
+func (l *actionLimit) matches(action models.Action, data map[string]interface{}) bool {
+	for field, value := range l.filters {
+		if actionField(action, data, field) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// key returns the counter an action is counted against
+func (l *actionLimit) key(action models.Action, data map[string]interface{}) string {
+	parts := []string{l.name}
+	for _, field := range l.per {
+		parts = append(parts, actionField(action, data, field))
+	}
+	return strings.Join(parts, "\x00")
+}
@@ -0,0 +1,105 @@
+// internal/rules/limits_test.go
+package rules
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bass4/dcs-ice/internal/config"
+	"github.com/bass4/dcs-ice/pkg/models"
+)
+
+func spawnAction(zone, count string) models.Action {
+	return models.Action{Type: "spawn", Zone: zone, UnitType: "T-72", Count: count, Rule: "Spawn"}
+}
+
+func TestActionLimiterCooldown(t *testing.T) {
+	limiter := newActionLimiter(compileActionLimits([]config.ActionLimitConfig{
+		{Name: "spawn-cooldown", ActionType: "spawn", Per: []string{"zone"}, CooldownSeconds: 300},
+	}), 0)
+
+	steps := []struct {
+		name   string
+		zone   string
+		now    int64
+		reason string
+	}{
+		{"first spawn", "BRAVO", 100, ""},
+		{"within cooldown", "BRAVO", 399, "cooldown, 1s left"},
+		{"other zone", "ALPHA", 399, ""},
+		{"cooldown expired", "BRAVO", 400, ""},
+		{"clock behind last action", "BRAVO", 350, "cooldown, 300s left"},
+	}
+	for _, step := range steps {
+		reason := limiter.allow(spawnAction(step.zone, "1"), step.now)
+		if step.reason == "" && reason != "" {
+			t.Errorf("%s: suppressed: %s", step.name, reason)
+		}
+		if step.reason != "" && !strings.Contains(reason, step.reason) {
+			t.Errorf("%s: got %q, want %q", step.name, reason, step.reason)
+		}
+	}
+}
+
+func TestActionLimiterBudgets(t *testing.T) {
+	limiter := newActionLimiter(compileActionLimits([]config.ActionLimitConfig{
+		{Name: "spawns", ActionType: "spawn", MaxActions: 3},
+		{Name: "units", ActionType: "spawn", Per: []string{"zone"}, MaxUnits: 5},
+	}), 0)
+
+	steps := []struct {
+		zone   string
+		count  string
+		reason string
+	}{
+		{"BRAVO", "2", ""},
+		{"BRAVO", "4", "budget of 5 units would be exceeded, 2 used"},
+		{"BRAVO", "3", ""},
+		{"ALPHA", "5", ""},
+		{"ALPHA", "1", "budget of 3 actions used"},
+	}
+	for i, step := range steps {
+		reason := limiter.allow(spawnAction(step.zone, step.count), 0)
+		if step.reason == "" && reason != "" {
+			t.Errorf("step %d: suppressed: %s", i, reason)
+		}
+		if step.reason != "" && !strings.Contains(reason, step.reason) {
+			t.Errorf("step %d: got %q, want %q", i, reason, step.reason)
+		}
+	}
+	if got := limiter.actions["spawns"]; got != 3 {
+		t.Errorf("spawns counted %d actions, want 3", got)
+	}
+	if got := limiter.units["units\x00BRAVO"]; got != 5 {
+		t.Errorf("BRAVO counted %d units, want 5", got)
+	}
+}
+
+func TestActionDedupWithoutTimestamps(t *testing.T) {
+	ruleEngine, _ := newTestEngineWithConfig(t, testRules, &config.Config{MaxCycles: 10, ActionDedup: 60})
+
+	// Events without timestamps leave the history's mission time at 0
+	sent := func() int {
+		result, err := ruleEngine.EvaluateMessage(testMessage("unit_destroyed", "BRAVO"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(result.Actions)
+	}
+	if n := sent(); n != 1 {
+		t.Fatalf("first spawn: %d actions sent, want 1", n)
+	}
+	if n := sent(); n != 0 {
+		t.Fatalf("duplicate spawn: %d actions sent, want 0", n)
+	}
+
+	// The wall clock carries mission time past the dedup window
+	queue := ruleEngine.missions.get("").queue
+	queue.mu.Lock()
+	queue.anchorAt = queue.anchorAt.Add(-61 * time.Second)
+	queue.mu.Unlock()
+	if n := sent(); n != 1 {
+		t.Errorf("spawn after the dedup window: %d actions sent, want 1", n)
+	}
+}
@@ -0,0 +1,31 @@
+// internal/rules/logging.go
+package rules
+
+import (
+	"io"
+
+	"github.com/hyperjumptech/grule-rule-engine/antlr"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/hyperjumptech/grule-rule-engine/builder"
+	"github.com/hyperjumptech/grule-rule-engine/engine"
+	"github.com/sirupsen/logrus"
+)
+
+// SetGruleLogger routes the grule library's own logging to out, keeping only
+// entries at or above level (debug, info, warn or error).
+func SetGruleLogger(out io.Writer, level string) error {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(out)
+	logger.SetLevel(lvl)
+
+	antlr.SetLogger(logger)
+	ast.SetLogger(logger)
+	builder.SetLogger(logger)
+	engine.SetLogger(logger)
+	return nil
+}
@@ -0,0 +1,210 @@
+// internal/rules/mission.go
+package rules
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bass4/dcs-ice/pkg/models"
+)
+
+// missionState is everything the engine remembers about one mission between evaluations
+type missionState struct {
+	world    *models.WorldState
+	history  *models.MessageHistory
+	patterns *patternMatcher
+	facts    *models.FactStore
+	limiter  *actionLimiter
+	queue    *actionQueue
+	actions  *models.ActionLog
+	lastUsed time.Time // Wall time the mission was last looked up
+}
+
+// maxMissions bounds the missions kept in memory; the least recently used one
+// is dropped first
+const maxMissions = 1000
+
+// missionSweepInterval is how often missions are checked for being idle
+const missionSweepInterval = time.Minute
+
+// missionRegistry holds the state of every mission seen since startup
+type missionRegistry struct {
+	mu            sync.Mutex
+	missions      map[string]*missionState
+	historyWindow int64 // Seconds of mission time kept in each history
+	patterns      []*pattern
+	limits        []*actionLimit
+	dedup         int64         // Seconds of mission time within which identical actions are suppressed
+	idle          time.Duration // Unused missions are dropped after this long; 0 keeps them
+	lastSweep     time.Time
+}
+
+func newMissionRegistry(historyWindow int64, patterns []*pattern, limits []*actionLimit, dedup int64, idle time.Duration) *missionRegistry {
+	return &missionRegistry{
+		missions:      make(map[string]*missionState),
+		historyWindow: historyWindow,
+		patterns:      patterns,
+		limits:        limits,
+		dedup:         dedup,
+		idle:          idle,
+	}
+}
+
+// get returns the state of a mission, creating it on first use
+func (mr *missionRegistry) get(missionID string) *missionState {
+	if missionID == "" {
+		missionID = models.DefaultMissionID
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	now := time.Now()
+	mr.sweep(now)
+	mission, ok := mr.missions[missionID]
+	if !ok {
+		if len(mr.missions) >= maxMissions {
+			mr.evictOldest()
+		}
+		mission = mr.newMission(missionID)
+		mr.missions[missionID] = mission
+	}
+	mission.lastUsed = now
+	return mission
+}
+
+// sweep drops the missions that were not used for the idle time and have no
+// scheduled actions left. Callers hold the lock.
+func (mr *missionRegistry) sweep(now time.Time) {
+	if mr.idle <= 0 || now.Sub(mr.lastSweep) < missionSweepInterval {
+		return
+	}
+	mr.lastSweep = now
+
+	for id, mission := range mr.missions {
+		if now.Sub(mission.lastUsed) >= mr.idle && mission.queue.size() == 0 {
+			delete(mr.missions, id)
+		}
+	}
+}
+
+// evictOldest drops the least recently used mission. Callers hold the lock.
+func (mr *missionRegistry) evictOldest() {
+	oldest := ""
+	for id, mission := range mr.missions {
+		if oldest == "" || mission.lastUsed.Before(mr.missions[oldest].lastUsed) {
+			oldest = id
+		}
+	}
+	delete(mr.missions, oldest)
+}
+
+// lookup returns the state of a mission without creating it, for requests
+// that name a mission that may not exist
+func (mr *missionRegistry) lookup(missionID string) (*missionState, bool) {
+	if missionID == "" {
+		missionID = models.DefaultMissionID
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	mission, ok := mr.missions[missionID]
+	if ok {
+		mission.lastUsed = time.Now()
+	}
+	return mission, ok
+}
+
+// list returns the state of every mission without marking them used
+func (mr *missionRegistry) list() []*missionState {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	missions := make([]*missionState, 0, len(mr.missions))
+	for _, mission := range mr.missions {
+		missions = append(missions, mission)
+	}
+	return missions
+}
+
+// reset replaces the state of a mission with an empty one
+func (mr *missionRegistry) reset(missionID string) {
+	if missionID == "" {
+		missionID = models.DefaultMissionID
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	mission := mr.newMission(missionID)
+	mission.lastUsed = time.Now()
+	mr.missions[missionID] = mission
+}
+
+func (mr *missionRegistry) newMission(missionID string) *missionState {
+	return &missionState{
+		world:    models.NewWorldState(missionID),
+		history:  models.NewMessageHistory(mr.historyWindow),
+		patterns: newPatternMatcher(mr.patterns),
+		facts:    models.NewFactStore(),
+		limiter:  newActionLimiter(mr.limits, mr.dedup),
+		queue:    newActionQueue(missionID),
+		actions:  models.NewActionLog(),
+	}
+}
+
+// World returns the world state of a mission. An empty ID selects the default mission.
+func (re *RuleEngine) World(missionID string) *models.WorldState {
+	return re.missions.get(missionID).world
+}
+
+// History returns the recent messages of a mission. An empty ID selects the default mission.
+func (re *RuleEngine) History(missionID string) *models.MessageHistory {
+	return re.missions.get(missionID).history
+}
+
+// Facts returns the working memory of a mission. An empty ID selects the default mission.
+func (re *RuleEngine) Facts(missionID string) *models.FactStore {
+	return re.missions.get(missionID).facts
+}
+
+// ResetWorld discards everything known about a mission: its world state, its
+// history, its partial pattern matches, its facts, its action limit counters,
+// its scheduled actions and its action log. This also happens when a
+// mission_start event arrives.
+func (re *RuleEngine) ResetWorld(missionID string) {
+	re.missions.reset(missionID)
+}
+
+// record adds a message to its mission's world state and history and feeds
+// it to the mission's patterns. It returns the mission and the messages
+// derived from completed patterns, which are added to the history as well.
+func (re *RuleEngine) record(message *models.Message) (*missionState, []*models.Message) {
+	if message.Event == "mission_start" {
+		re.missions.reset(message.MissionID)
+	}
+	mission := re.missions.get(message.MissionID)
+	mission.world.Apply(message)
+
+	// Messages without a timestamp are filed at the mission clock's time, so
+	// the history window moves on between timestamped events too
+	mission.queue.observe(mission.timeOf(message), message.Timestamp != 0)
+	at := message.Timestamp
+	if at == 0 {
+		at = mission.queue.clock()
+	}
+	mission.history.AddAt(message, at)
+	derived := mission.patterns.observe(message, at)
+	for _, d := range derived {
+		fmt.Fprintf(re.out, "Pattern %s completed: Event=%s, Zone=%s\n", d.Data["pattern"], d.Event, d.Zone)
+		mission.history.Add(d)
+	}
+	return mission, derived
+}
+
+// timeOf returns the mission time of a message: its timestamp, or the
+// current mission time for messages without one
+func (ms *missionState) timeOf(message *models.Message) int64 {
+	if message.Timestamp != 0 {
+		return message.Timestamp
+	}
+	return ms.history.Now()
+}
@@ -0,0 +1,74 @@
+// internal/rules/mission_test.go
+package rules
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bass4/dcs-ice/internal/config"
+)
+
+func TestMissionRegistryDropsIdleMissions(t *testing.T) {
+	registry := newMissionRegistry(300, nil, nil, 0, time.Hour)
+	idle := registry.get("idle")
+	busy := registry.get("busy")
+	busy.queue.add(&ScheduledAction{ID: "act-1", DueAt: 60})
+
+	// Pretend both missions were last used two hours ago
+	past := time.Now().Add(-2 * time.Hour)
+	idle.lastUsed, busy.lastUsed = past, past
+	registry.lastSweep = past
+
+	registry.get("other")
+	if _, ok := registry.missions["idle"]; ok {
+		t.Error("idle mission was kept")
+	}
+	if _, ok := registry.missions["busy"]; !ok {
+		t.Error("mission with scheduled actions was dropped")
+	}
+	if registry.get("idle") == idle {
+		t.Error("dropped mission was not recreated empty")
+	}
+}
+
+func TestMissionRegistryCap(t *testing.T) {
+	registry := newMissionRegistry(300, nil, nil, 0, 0)
+	for i := 0; i < maxMissions; i++ {
+		registry.get(fmt.Sprintf("mission-%d", i))
+	}
+	registry.missions["mission-0"].lastUsed = time.Now().Add(-time.Minute)
+
+	registry.get("one-too-many")
+	if len(registry.missions) != maxMissions {
+		t.Errorf("%d missions kept, want %d", len(registry.missions), maxMissions)
+	}
+	if _, ok := registry.missions["mission-0"]; ok {
+		t.Error("least recently used mission was kept")
+	}
+}
+
+func TestHistoryPrunesUntimestampedMessages(t *testing.T) {
+	ruleEngine, _ := newTestEngineWithConfig(t, testRules, &config.Config{MaxCycles: 10, HistoryWindow: 5})
+	for i := 0; i < 3; i++ {
+		if _, err := ruleEngine.EvaluateMessage(testMessage("unit_detected", "CHARLIE")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	history := ruleEngine.History("")
+	if history.Len() != 3 {
+		t.Fatalf("history has %d messages, want 3", history.Len())
+	}
+
+	// Ten minutes of wall time pass before the next untimestamped event
+	queue := ruleEngine.missions.get("").queue
+	queue.mu.Lock()
+	queue.anchorAt = queue.anchorAt.Add(-10 * time.Minute)
+	queue.mu.Unlock()
+	if _, err := ruleEngine.EvaluateMessage(testMessage("unit_detected", "CHARLIE")); err != nil {
+		t.Fatal(err)
+	}
+	if history.Len() != 1 {
+		t.Errorf("history has %d messages, want only the latest", history.Len())
+	}
+}
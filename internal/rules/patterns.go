@@ -0,0 +1,289 @@
+// internal/rules/patterns.go
+package rules
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/bass4/dcs-ice/internal/config"
+	"github.com/bass4/dcs-ice/pkg/models"
+)
+
+// maxPartialMatches bounds the partial matches kept per mission; the oldest are dropped first
+const maxPartialMatches = 1000
+
+// pattern is a compiled event pattern
+type pattern struct {
+	name         string
+	emit         string
+	steps        []patternStep
+	within       int64
+	absent       *patternStep
+	absentWithin int64
+	correlateBy  string
+}
+
+// patternStep matches one event of a pattern
+type patternStep struct {
+	event     string
+	zone      string
+	coalition string
+	match     map[string]string
+}
+
+// matches reports whether a message satisfies the step
+func (s *patternStep) matches(m *models.Message) bool {
+	if m.Event != s.event {
+		return false
+	}
+	if s.zone != "" && !m.InZone(s.zone) {
+		return false
+	}
+	if s.coalition != "" && !m.Coalition.Is(s.coalition) {
+		return false
+	}
+	for path, value := range s.match {
+		if m.GetString(path) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// correlationKey returns the value that ties the events of one match together
+func (p *pattern) correlationKey(m *models.Message) string {
+	switch p.correlateBy {
+	case "":
+		return ""
+	case "unit_name":
+		return m.UnitName
+	case "group_name":
+		return m.GroupName
+	}
+	return m.GetString(p.correlateBy)
+}
+
+// compilePatterns converts the validated pattern configuration
+func compilePatterns(patternConfigs []config.PatternConfig) []*pattern {
+	patterns := make([]*pattern, 0, len(patternConfigs))
+	for _, pc := range patternConfigs {
+		p := &pattern{
+			name:        pc.Name,
+			emit:        pc.Emit,
+			within:      pc.WithinSeconds,
+			correlateBy: pc.CorrelateBy,
+		}
+		if p.emit == "" {
+			p.emit = pc.Name
+		}
+		for _, sc := range pc.Steps {
+			p.steps = append(p.steps, compileStep(sc))
+		}
+		if pc.Absent != nil {
+			absent := compileStep(pc.Absent.PatternStepConfig)
+			p.absent = &absent
+			p.absentWithin = pc.Absent.WithinSeconds
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns
+}
+
+func compileStep(sc config.PatternStepConfig) patternStep {
+	return patternStep{event: sc.Event, zone: sc.Zone, coalition: sc.Coalition, match: sc.Match}
+}
+
+// partialMatch is a pattern whose first steps have been seen
+type partialMatch struct {
+	pattern   *pattern
+	key       string
+	next      int   // Index of the next step to match
+	startedAt int64 // Mission time of the first step
+	deadline  int64 // For absence patterns whose steps are complete: end of the absence window
+	last      *models.Message
+}
+
+// patternMatcher tracks the partial matches of every pattern for one mission
+type patternMatcher struct {
+	mu       sync.Mutex
+	patterns []*pattern
+	partials []*partialMatch
+}
+
+func newPatternMatcher(patterns []*pattern) *patternMatcher {
+	return &patternMatcher{patterns: patterns}
+}
+
+// observe advances the partial matches with a message at mission time now and
+// returns a derived message for every pattern that completed. Absence
+// patterns complete when a later message or expire moves mission time past
+// the end of their absence window. A pattern completes at most once per
+// correlation key for each message.
+func (pm *patternMatcher) observe(m *models.Message, now int64) []*models.Message {
+	if len(pm.patterns) == 0 {
+		return nil
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	derived := pm.completeAbsent(now)
+	kept := pm.partials[:0]
+	for _, partial := range pm.partials {
+		p := partial.pattern
+
+		// Steps complete, waiting for the absence window to pass
+		if partial.deadline != 0 {
+			if p.absent.matches(m) && p.correlationKey(m) == partial.key {
+				continue
+			}
+			kept = append(kept, partial)
+			continue
+		}
+
+		if now-partial.startedAt > p.within {
+			continue
+		}
+		if p.steps[partial.next].matches(m) && p.correlationKey(m) == partial.key {
+			partial.next++
+			partial.last = m
+			if partial.next == len(p.steps) {
+				if p.absent == nil {
+					derived = append(derived, partial.derive(now))
+					continue
+				}
+				partial.deadline = now + p.absentWithin
+			}
+		}
+		kept = append(kept, partial)
+	}
+	pm.partials = kept
+
+	// Every message matching a first step starts a match; dedupe keeps the latest
+	for _, p := range pm.patterns {
+		if !p.steps[0].matches(m) {
+			continue
+		}
+		key := p.correlationKey(m)
+		if p.correlateBy != "" && key == "" {
+			continue
+		}
+		partial := &partialMatch{pattern: p, key: key, next: 1, startedAt: now, last: m}
+		if len(p.steps) == 1 {
+			if p.absent == nil {
+				derived = append(derived, partial.derive(now))
+				continue
+			}
+			partial.deadline = now + p.absentWithin
+		}
+		pm.partials = append(pm.partials, partial)
+	}
+
+	pm.partials = dedupe(pm.partials)
+
+	if excess := len(pm.partials) - maxPartialMatches; excess > 0 {
+		pm.partials = append(pm.partials[:0], pm.partials[excess:]...)
+	}
+
+	return derived
+}
+
+// expire completes the absence patterns whose window ended before mission
+// time now and returns their derived messages. It runs on a timer, so that
+// missions without further events emit them too.
+func (pm *patternMatcher) expire(now int64) []*models.Message {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	return pm.completeAbsent(now)
+}
+
+// completeAbsent removes the matches whose absence window ended before
+// mission time now and returns their derived messages. Callers hold the lock.
+func (pm *patternMatcher) completeAbsent(now int64) []*models.Message {
+	var derived []*models.Message
+	kept := pm.partials[:0]
+	for _, partial := range pm.partials {
+		if partial.deadline != 0 && now > partial.deadline {
+			derived = append(derived, partial.derive(partial.deadline))
+			continue
+		}
+		kept = append(kept, partial)
+	}
+	pm.partials = kept
+	return derived
+}
+
+// partialState is what a partial match still needs to complete
+type partialState struct {
+	pattern *pattern
+	key     string
+	next    int
+	waiting bool
+}
+
+// dedupe keeps one partial match per pattern, correlation key and next step:
+// the latest started, which has the most time left to complete. Of the
+// matches waiting for their absence window the first is kept, so a pattern
+// emits once per window.
+func dedupe(partials []*partialMatch) []*partialMatch {
+	index := make(map[partialState]int, len(partials))
+	kept := make([]*partialMatch, 0, len(partials))
+	for _, partial := range partials {
+		state := partialState{partial.pattern, partial.key, partial.next, partial.deadline != 0}
+		i, seen := index[state]
+		if !seen {
+			index[state] = len(kept)
+			kept = append(kept, partial)
+			continue
+		}
+		if !state.waiting && partial.startedAt >= kept[i].startedAt {
+			kept[i] = partial
+		}
+	}
+	return kept
+}
+
+// derive builds the message emitted for a completed match. It describes the
+// unit, group and location of the last matched event.
+func (pm *partialMatch) derive(at int64) *models.Message {
+	last := pm.last
+	derived := models.NewMessage(pm.pattern.emit)
+	derived.MissionID = last.MissionID
+	derived.Timestamp = at
+	derived.Zone = last.Zone
+	derived.Zones = last.Zones
+	derived.UnitType = last.UnitType
+	derived.UnitName = last.UnitName
+	derived.GroupName = last.GroupName
+	derived.Coalition = last.Coalition
+	derived.Position = last.Position
+	derived.HasPosition = last.HasPosition
+	derived.Data = map[string]interface{}{
+		"pattern":         pm.pattern.name,
+		"correlation_key": pm.key,
+		"started_at":      float64(pm.startedAt),
+	}
+	return derived
+}
+
+// CompleteAbsencePatterns completes the absence patterns of every mission
+// whose window passed without the absent event, also when the mission has
+// been quiet since, and evaluates the derived messages with the single-event
+// rules. Their actions are queued due at once, so they are delivered like
+// scheduled actions that came due. It is meant to run on a timer.
+func (re *RuleEngine) CompleteAbsencePatterns() {
+	for _, mission := range re.missions.list() {
+		for _, d := range mission.patterns.expire(mission.queue.clock()) {
+			fmt.Fprintf(re.out, "Pattern %s completed: Event=%s, Zone=%s\n", d.Data["pattern"], d.Event, d.Zone)
+			mission.history.Add(d)
+
+			result, err := re.evaluateSingle(d, mission)
+			if err != nil {
+				fmt.Fprintf(re.out, "Evaluation of %s stopped: %v\n", d.Event, err)
+			}
+			if result != nil {
+				mission.queueNow(result.Actions)
+			}
+		}
+	}
+}
@@ -0,0 +1,188 @@
+// internal/rules/patterns_test.go
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bass4/dcs-ice/internal/config"
+	"github.com/bass4/dcs-ice/pkg/models"
+)
+
+// patternEvent is a message observed at a mission time. An empty event is a
+// timer check without a message.
+type patternEvent struct {
+	event, unit string
+	at          int64
+}
+
+func TestPatternMatcher(t *testing.T) {
+	sequence := config.PatternConfig{
+		Name:          "strike",
+		Steps:         []config.PatternStepConfig{{Event: "unit_detected"}, {Event: "unit_destroyed"}},
+		WithinSeconds: 60,
+	}
+	correlated := sequence
+	correlated.CorrelateBy = "unit_name"
+	absence := config.PatternConfig{
+		Name:   "unanswered",
+		Steps:  []config.PatternStepConfig{{Event: "unit_detected"}},
+		Absent: &config.PatternAbsentConfig{PatternStepConfig: config.PatternStepConfig{Event: "unit_destroyed"}, WithinSeconds: 30},
+	}
+
+	tests := []struct {
+		name    string
+		pattern config.PatternConfig
+		events  []patternEvent
+		want    []string // Correlation keys of the derived messages, in order
+	}{
+		{
+			name:    "repeated first step completes once",
+			pattern: sequence,
+			events:  []patternEvent{{"unit_detected", "a", 0}, {"unit_detected", "a", 5}, {"unit_detected", "a", 10}, {"unit_destroyed", "a", 20}},
+			want:    []string{""},
+		},
+		{
+			name:    "latest first step keeps the window open",
+			pattern: sequence,
+			events:  []patternEvent{{"unit_detected", "a", 0}, {"unit_detected", "a", 50}, {"unit_destroyed", "a", 70}},
+			want:    []string{""},
+		},
+		{
+			name:    "one match per correlation key",
+			pattern: correlated,
+			events:  []patternEvent{{"unit_detected", "a", 0}, {"unit_detected", "b", 1}, {"unit_detected", "a", 2}, {"unit_destroyed", "a", 3}, {"unit_destroyed", "b", 4}},
+			want:    []string{"a", "b"},
+		},
+		{
+			name:    "completed match does not complete again",
+			pattern: sequence,
+			events:  []patternEvent{{"unit_detected", "a", 0}, {"unit_destroyed", "a", 1}, {"unit_destroyed", "a", 2}},
+			want:    []string{""},
+		},
+		{
+			name:    "absence window emits once",
+			pattern: absence,
+			events:  []patternEvent{{"unit_detected", "a", 0}, {"unit_detected", "a", 10}, {"unit_detected", "a", 20}, {"mission_tick", "", 100}},
+			want:    []string{""},
+		},
+		{
+			name:    "absent event cancels the match",
+			pattern: absence,
+			events:  []patternEvent{{"unit_detected", "a", 0}, {"unit_destroyed", "a", 10}, {"mission_tick", "", 100}},
+		},
+		{
+			name:    "quiet mission emits on the timer",
+			pattern: absence,
+			events:  []patternEvent{{"unit_detected", "a", 0}, {"", "", 30}, {"", "", 31}, {"", "", 60}},
+			want:    []string{""},
+		},
+		{
+			name:    "absent event before the timer cancels the match",
+			pattern: absence,
+			events:  []patternEvent{{"unit_detected", "a", 0}, {"unit_destroyed", "a", 10}, {"", "", 31}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := newPatternMatcher(compilePatterns([]config.PatternConfig{tt.pattern}))
+
+			var got []string
+			for _, e := range tt.events {
+				var derived []*models.Message
+				if e.event == "" {
+					derived = matcher.expire(e.at)
+				} else {
+					message := models.NewMessage(e.event)
+					message.UnitName = e.unit
+					derived = matcher.observe(message, e.at)
+				}
+				for _, d := range derived {
+					got = append(got, d.GetString("correlation_key"))
+				}
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("derived %d messages %q, want %d %q", len(got), got, len(tt.want), tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("derived message %d has key %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+			if len(matcher.partials) > len(tt.pattern.Steps)*2 {
+				t.Errorf("%d partial matches kept", len(matcher.partials))
+			}
+		})
+	}
+}
+
+func TestPatternStepCoalition(t *testing.T) {
+	step := compileStep(config.PatternStepConfig{Event: "unit_detected", Coalition: "blue"})
+	for _, tt := range []struct {
+		coalition models.Coalition
+		want      bool
+	}{
+		{models.CoalitionBlue, true},
+		{"BLUE", true},
+		{"2", true},
+		{models.CoalitionRed, false},
+		{"", false},
+	} {
+		message := models.NewMessage("unit_detected")
+		message.Coalition = tt.coalition
+		if got := step.matches(message); got != tt.want {
+			t.Errorf("step for blue matches coalition %q: %v, want %v", tt.coalition, got, tt.want)
+		}
+	}
+}
+
+func TestCompleteAbsencePatterns(t *testing.T) {
+	ruleEngine, _ := newTestEngineWithConfig(t, `
+rule Unanswered "A detection nobody answered" {
+    when
+        Message.Event == "unanswered"
+    then
+        Actions.AddAlertAction("unanswered", "yellow", "No response in ALPHA");
+        Retract("Unanswered");
+}
+`, &config.Config{
+		MaxCycles: 10,
+		Patterns: []config.PatternConfig{{
+			Name:   "unanswered",
+			Steps:  []config.PatternStepConfig{{Event: "unit_detected", Coalition: "red"}},
+			Absent: &config.PatternAbsentConfig{PatternStepConfig: config.PatternStepConfig{Event: "unit_destroyed"}, WithinSeconds: 30},
+		}},
+	})
+
+	detection := testMessage("unit_detected", "ALPHA")
+	detection.Coalition = models.CoalitionRed
+	detection.Timestamp = 100
+	if _, err := ruleEngine.EvaluateMessage(detection); err != nil {
+		t.Fatal(err)
+	}
+
+	ruleEngine.CompleteAbsencePatterns()
+	if due := ruleEngine.DueActions(""); len(due) != 0 {
+		t.Fatalf("%d actions before the absence window passed", len(due))
+	}
+
+	// No event arrives while 31 seconds of wall time pass
+	queue := ruleEngine.missions.get("").queue
+	queue.mu.Lock()
+	queue.anchorAt = queue.anchorAt.Add(-31 * time.Second)
+	queue.mu.Unlock()
+	ruleEngine.CompleteAbsencePatterns()
+
+	due := ruleEngine.DueActions("")
+	if len(due) != 1 || due[0].Rule != "Unanswered" {
+		t.Fatalf("got due actions %+v, want the alert of Unanswered", due)
+	}
+	if record, _ := ruleEngine.ActionLog("").Get(due[0].ID); record.Status != models.ActionSent {
+		t.Errorf("delivered action is %s, want sent", record.Status)
+	}
+	if ruleEngine.History("").Within(60).CountMessagesByEvent("unanswered") != 1 {
+		t.Error("derived message was not added to the history")
+	}
+}
@@ -2,9 +2,15 @@
 package rules
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/hyperjumptech/grule-rule-engine/ast"
 	"github.com/hyperjumptech/grule-rule-engine/builder"
@@ -22,24 +28,98 @@ const (
 
 // RuleEngine handles rule evaluation
 type RuleEngine struct {
+	mu            sync.RWMutex
+	reloadMu      sync.Mutex // serializes LoadRules so reloads never interleave
+	rules         *ruleSet
+	rulesDirs     []string
+	rulesFiles    []string
+	maxCycles     uint64
+	zones         *models.ZoneRegistry
+	missions      *missionRegistry
+	actionSchemas *models.ActionSchemaRegistry
+	out           io.Writer // Where loading and evaluations are reported
+}
+
+// ruleSet is a fully compiled set of rules. It is never modified once built;
+// a reload builds a new one and swaps it in. Every evaluation context has its
+// own knowledge base holding only the rules that apply to it.
+type ruleSet struct {
+	version  uint64
+	files    []string
+	contexts map[EvaluationContext]*contextRules
+}
+
+// contextRules is the knowledge base of a single evaluation context.
+//
+// The knowledge base in the library is only a blueprint. Evaluations run on
+// instances cloned from it; an instance carries per-run state, so each one is
+// used by a single evaluation at a time and returned to the pool afterwards.
+type contextRules struct {
 	knowledgeLibrary *ast.KnowledgeLibrary
-	engine           *engine.GruleEngine
-	rulesDirs        []string
-	rulesFiles       []string
-	maxCycles        uint64
+	rules            []string
+	instances        sync.Pool
+}
+
+// acquire returns a knowledge base instance for exclusive use by one evaluation
+func (cr *contextRules) acquire() (*ast.KnowledgeBase, error) {
+	if kb, ok := cr.instances.Get().(*ast.KnowledgeBase); ok {
+		return kb, nil
+	}
+	return cr.knowledgeLibrary.NewKnowledgeBaseInstance(KnowledgeBaseName, KnowledgeBaseVersion)
+}
+
+// release hands a knowledge base instance back to the pool
+func (cr *contextRules) release(kb *ast.KnowledgeBase) {
+	cr.instances.Put(kb)
 }
 
-// NewRuleEngine creates a new rule engine
+// RuleFileError holds the compile errors of a single rule file
+type RuleFileError struct {
+	File   string   `json:"file"`
+	Errors []string `json:"errors"`
+}
+
+// ReloadError is returned when one or more rule files fail to compile.
+// The previously loaded rules stay active.
+type ReloadError struct {
+	Files []RuleFileError
+}
+
+func (e *ReloadError) Error() string {
+	if len(e.Files) == 1 {
+		return fmt.Sprintf("failed to build rules from file %s: %s", e.Files[0].File, strings.Join(e.Files[0].Errors, "; "))
+	}
+	return fmt.Sprintf("failed to build rules from %d files", len(e.Files))
+}
+
+// NewRuleEngine creates a new rule engine that reports on stdout
 func NewRuleEngine(cfg *config.Config) (*RuleEngine, error) {
-	knowledgeLibrary := ast.NewKnowledgeLibrary()
-	gruleEngine := engine.NewGruleEngine()
-	
+	return NewRuleEngineWithOutput(cfg, os.Stdout)
+}
+
+// NewRuleEngineWithOutput creates a new rule engine that reports loading and
+// evaluations on out, e.g. io.Discard for command line tools
+func NewRuleEngineWithOutput(cfg *config.Config, out io.Writer) (*RuleEngine, error) {
 	re := &RuleEngine{
-		knowledgeLibrary: knowledgeLibrary,
-		engine:           gruleEngine,
-		rulesDirs:        cfg.RulesDirs,
-		rulesFiles:       cfg.RulesFiles,
-		maxCycles:        cfg.MaxCycles,
+		rulesDirs:     cfg.RulesDirs,
+		rulesFiles:    cfg.RulesFiles,
+		maxCycles:     cfg.MaxCycles,
+		zones:         newZoneRegistry(cfg.Zones),
+		missions:      newMissionRegistry(int64(cfg.HistoryWindow)*60, compilePatterns(cfg.Patterns), compileActionLimits(cfg.ActionLimits), cfg.ActionDedup, time.Duration(cfg.MissionIdle)*time.Minute),
+		actionSchemas: NewActionSchemaRegistry(cfg.ActionSchemas),
+		out:           out,
+	}
+	if re.zones.Len() > 0 {
+		fmt.Fprintf(re.out, "Loaded %d zones\n", re.zones.Len())
+	}
+	if len(cfg.Patterns) > 0 {
+		fmt.Fprintf(re.out, "Loaded %d event patterns\n", len(cfg.Patterns))
+	}
+	if len(cfg.ActionSchemas) > 0 {
+		fmt.Fprintf(re.out, "Loaded %d action schemas\n", len(cfg.ActionSchemas))
+	}
+	if len(cfg.ActionLimits) > 0 {
+		fmt.Fprintf(re.out, "Loaded %d action limits\n", len(cfg.ActionLimits))
 	}
 	
 	// Load rules
@@ -50,103 +130,362 @@ func NewRuleEngine(cfg *config.Config) (*RuleEngine, error) {
 	return re, nil
 }
 
-// LoadRules loads rules from the configured directories and files
+// LoadRules compiles the rules from the configured directories and files into a
+// new knowledge base and makes it active. If any file fails to compile or
+// redeclares a rule of an earlier file the currently active rules are kept and
+// a *ReloadError lists every failing file.
 func (re *RuleEngine) LoadRules() error {
-	ruleBuilder := builder.NewRuleBuilder(re.knowledgeLibrary)
+	re.reloadMu.Lock()
+	defer re.reloadMu.Unlock()
 	
-	// Track rule count
-	ruleCount := 0
+	files, err := collectRuleFiles(re.rulesDirs, re.rulesFiles)
+	if err != nil {
+		return err
+	}
 	
-	// Load rules from specified directories
-	for _, dir := range re.rulesDirs {
-		files, err := os.ReadDir(dir)
+	if len(files) == 0 {
+		return fmt.Errorf("no rule files (.grl) found in specified directories or files")
+	}
+	
+	contexts := make(map[EvaluationContext]*contextRules)
+	for _, ctx := range evaluationContexts {
+		contexts[ctx] = &contextRules{knowledgeLibrary: ast.NewKnowledgeLibrary()}
+	}
+	
+	// Every file is built into each context's library; rules that do not
+	// apply to a context are removed from its knowledge base afterwards
+	ruleContextsByName := make(map[string][]EvaluationContext)
+	declarations := make(ruleDeclarations)
+	var fileErrors []RuleFileError
+	for _, filePath := range files {
+		fmt.Fprintf(re.out, "Loading rule file: %s\n", filePath)
+		source, err := os.ReadFile(filePath)
 		if err != nil {
-			return fmt.Errorf("failed to read rules directory %s: %v", dir, err)
+			fileErrors = append(fileErrors, RuleFileError{File: filePath, Errors: []string{err.Error()}})
+			continue
 		}
 		
-		for _, file := range files {
-			if !file.IsDir() && filepath.Ext(file.Name()) == ".grl" {
-				filePath := filepath.Join(dir, file.Name())
-				ruleFile := pkg.NewFileResource(filePath)
-				
-				fmt.Printf("Loading rule file: %s\n", filePath)
-				err := ruleBuilder.BuildRuleFromResource(KnowledgeBaseName, KnowledgeBaseVersion, ruleFile)
-				if err != nil {
-					return fmt.Errorf("failed to build rule from file %s: %v", filePath, err)
-				}
-				ruleCount++
+		var buildErrors []string
+		for _, ctx := range evaluationContexts {
+			ruleBuilder := builder.NewRuleBuilder(contexts[ctx].knowledgeLibrary)
+			if err := ruleBuilder.BuildRuleFromResource(KnowledgeBaseName, KnowledgeBaseVersion, pkg.NewBytesResource(source)); err != nil {
+				buildErrors = compileErrors(err)
+				break
 			}
 		}
+		
+		fileContexts, diagnostics := ruleContexts(filePath, string(source))
+		var declared []Diagnostic
+		for _, rule := range scanRuleFacts(filePath, string(source)) {
+			declared = append(declared, rule.Diagnostic)
+		}
+		diagnostics = append(diagnostics, declarations.declare(declared)...)
+		for _, d := range diagnostics {
+			buildErrors = append(buildErrors, fmt.Sprintf("%d:%d: %s", d.Line, d.Column, d.Message))
+		}
+		if len(buildErrors) > 0 {
+			fileErrors = append(fileErrors, RuleFileError{File: filePath, Errors: buildErrors})
+			continue
+		}
+		
+		for rule, ctxs := range fileContexts {
+			ruleContextsByName[rule] = ctxs
+		}
 	}
 	
-	// Load specific rule files
-	for _, filePath := range re.rulesFiles {
-		ruleFile := pkg.NewFileResource(filePath)
+	if len(fileErrors) > 0 {
+		return &ReloadError{Files: fileErrors}
+	}
+	
+	for _, ctx := range evaluationContexts {
+		cr := contexts[ctx]
+		for rule, ctxs := range ruleContextsByName {
+			if containsContext(ctxs, ctx) {
+				cr.rules = append(cr.rules, rule)
+			} else {
+				cr.knowledgeLibrary.RemoveRuleEntry(rule, KnowledgeBaseName, KnowledgeBaseVersion)
+			}
+		}
+		sort.Strings(cr.rules)
 		
-		fmt.Printf("Loading rule file: %s\n", filePath)
-		err := ruleBuilder.BuildRuleFromResource(KnowledgeBaseName, KnowledgeBaseVersion, ruleFile)
+		// Create the first instance up front so a rule set that cannot be instantiated is never activated
+		kb, err := cr.acquire()
 		if err != nil {
-			return fmt.Errorf("failed to build rule from file %s: %v", filePath, err)
+			return fmt.Errorf("failed to create %s knowledge base instance: %v", ctx, err)
 		}
-		ruleCount++
+		cr.release(kb)
 	}
 	
-	if ruleCount == 0 {
-		return fmt.Errorf("no rule files (.grl) found in specified directories or files")
+	rules := &ruleSet{
+		files:    files,
+		contexts: contexts,
 	}
 	
-	fmt.Printf("Loaded %d rule files\n", ruleCount)
+	re.mu.Lock()
+	rules.version = 1
+	if re.rules != nil {
+		rules.version = re.rules.version + 1
+	}
+	re.rules = rules
+	version := rules.version
+	re.mu.Unlock()
+	
+	fmt.Fprintf(re.out, "Loaded %d rule files (rule set version %d): %d single rules, %d batch rules\n",
+		len(files), version, len(contexts[ContextSingle].rules), len(contexts[ContextBatch].rules))
 	return nil
 }
 
+// Rules returns the names of the active rules that run in the given context
+func (re *RuleEngine) Rules(ctx EvaluationContext) []string {
+	cr, ok := re.currentRules().contexts[ctx]
+	if !ok {
+		return nil
+	}
+	return append([]string(nil), cr.rules...)
+}
+
+func containsContext(contexts []EvaluationContext, ctx EvaluationContext) bool {
+	for _, c := range contexts {
+		if c == ctx {
+			return true
+		}
+	}
+	return false
+}
+
 // ReloadRules reloads all rules from the configured directories and files
 func (re *RuleEngine) ReloadRules() error {
 	return re.LoadRules()
 }
 
-// ProcessMessage processes a DCS message through the rules engine
+// Version returns the version of the active rule set. It starts at 1 and is
+// incremented on every successful reload.
+func (re *RuleEngine) Version() uint64 {
+	return re.currentRules().version
+}
+
+// currentRules returns the active rule set
+func (re *RuleEngine) currentRules() *ruleSet {
+	re.mu.RLock()
+	defer re.mu.RUnlock()
+	return re.rules
+}
+
+// collectRuleFiles lists the .grl files in the given directories and their
+// single and batch subdirectories, followed by the given files
+func collectRuleFiles(dirs, files []string) ([]string, error) {
+	var result []string
+	
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rules directory %s: %v", dir, err)
+		}
+		
+		for _, entry := range entries {
+			if entry.IsDir() {
+				if _, ok := parseContext(entry.Name()); ok {
+					nested, err := collectRuleFiles([]string{filepath.Join(dir, entry.Name())}, nil)
+					if err != nil {
+						return nil, err
+					}
+					result = append(result, nested...)
+				}
+				continue
+			}
+			if filepath.Ext(entry.Name()) == ".grl" {
+				result = append(result, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+	
+	return append(result, files...), nil
+}
+
+// compileErrors flattens a rule builder error into one message per problem
+func compileErrors(err error) []string {
+	var reporter *pkg.GruleErrorReporter
+	if errors.As(err, &reporter) && reporter.HasError() {
+		messages := make([]string, 0, len(reporter.Errors))
+		for _, e := range reporter.Errors {
+			messages = append(messages, e.Error())
+		}
+		return messages
+	}
+	return []string{err.Error()}
+}
+
+// execute runs the active rules of ctx against dataContext and traces which
+// rules fired. Actions that do not match their schema are dropped. Engine failures are returned as *CycleExhaustedError,
+// *RuleConditionError or *RuleRuntimeError alongside the partial result. Each call gets its own engine and knowledge base instance, so
+// concurrent calls share no mutable state.
+func (re *RuleEngine) execute(ctx EvaluationContext, dataContext ast.IDataContext, actionCollector *models.ActionCollector) (*EvaluationResult, error) {
+	rules := re.currentRules()
+	contextRules := rules.contexts[ctx]
+	
+	kb, err := contextRules.acquire()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s knowledge base instance: %v", ctx, err)
+	}
+	defer contextRules.release(kb)
+	
+	trace := newTraceListener(actionCollector)
+	
+	gruleEngine := engine.NewGruleEngine()
+	gruleEngine.MaxCycle = re.maxCycles
+	gruleEngine.Listeners = []engine.GruleEngineListener{trace}
+	gruleEngine.ReturnErrOnFailedRuleEvaluation = true
+	
+	err = classifyExecuteError(gruleEngine.Execute(dataContext, kb), trace, kb, dataContext, re.maxCycles)
+	
+	result := trace.result()
+	result.RuleSetVersion = rules.version
+	
+	// Nothing that does not match its schema reaches DCS
+	result.dropInvalid(re.actionSchemas)
+	return result, err
+}
+
+// ProcessMessage processes a DCS message through the rules engine.
+// On a *CycleExhaustedError, *RuleConditionError or *RuleRuntimeError the actions collected so far
+// are returned together with the error.
 func (re *RuleEngine) ProcessMessage(message *models.Message) ([]models.Action, error) {
-	fmt.Printf("Processing message: Event=%s, Zone=%s\n", message.Event, message.Zone)
+	result, err := re.EvaluateMessage(message)
+	if result == nil {
+		return nil, err
+	}
+	return result.Actions, err
+}
+
+// ProcessMessages processes multiple DCS messages through the rules engine.
+// Errors are reported as for ProcessMessage.
+func (re *RuleEngine) ProcessMessages(messages []*models.Message) ([]models.Action, error) {
+	result, err := re.EvaluateMessages(messages)
+	if result == nil {
+		return nil, err
+	}
+	return result.Actions, err
+}
+
+// EvaluateMessage processes a DCS message through the rules engine and
+// returns the actions together with the rules that produced them. The zones
+// containing the message's position are resolved first. Messages derived
+// from patterns the message completed are evaluated after it, and their
+// results are merged into its result, also when the message's own evaluation
+// stopped early. The errors of all evaluations are returned as EvaluationErrors.
+// A *DataContextError means nothing was evaluated and the result is nil; a
+// *CycleExhaustedError, *RuleConditionError or *RuleRuntimeError comes with a
+// partial result.
+func (re *RuleEngine) EvaluateMessage(message *models.Message) (*EvaluationResult, error) {
+	re.zones.ResolveZones(message)
+	fmt.Fprintf(re.out, "Processing message: Event=%s, Zone=%s\n", message.Event, message.Zone)
 	
-	// Get the knowledge base
-	kb := re.knowledgeLibrary.GetKnowledgeBase(KnowledgeBaseName, KnowledgeBaseVersion)
+	// Record the event in the mission's world state and history before the rules look at it
+	mission, derived := re.record(message)
+	
+	result, err := re.evaluateSingle(message, mission)
+	if result == nil {
+		// The derived messages would fail to set up the same facts
+		return nil, err
+	}
+	
+	for _, d := range derived {
+		derivedResult, derivedErr := re.evaluateSingle(d, mission)
+		if derivedResult != nil {
+			result.merge(derivedResult, d.Event)
+		}
+		err = joinErrors(err, derivedErr)
+	}
+	
+	// Hand out the scheduled actions that came due with this event
+	result.Due = mission.due()
+	return result, err
+}
+
+// EvaluateFacts runs the single-event rules after facts of a mission were
+// asserted. The rules see a facts_changed message that is not recorded in
+// the mission's world state or history.
+func (re *RuleEngine) EvaluateFacts(missionID string) (*EvaluationResult, error) {
+	if missionID == "" {
+		missionID = models.DefaultMissionID
+	}
+	fmt.Fprintf(re.out, "Processing facts: Mission=%s\n", missionID)
 	
+	message := models.NewMessage("facts_changed")
+	message.MissionID = missionID
+	mission := re.missions.get(missionID)
+	result, err := re.evaluateSingle(message, mission)
+	if result != nil {
+		result.Due = mission.due()
+	}
+	return result, err
+}
+
+// evaluateSingle runs the single-event rules against one recorded message
+func (re *RuleEngine) evaluateSingle(message *models.Message, mission *missionState) (*EvaluationResult, error) {
 	// Create an ActionCollector to store actions
-	actionCollector := models.NewActionCollector()
+	actionCollector := models.NewActionCollectorWithLog(mission.actions)
 	
 	// Create data context
 	dataContext := ast.NewDataContext()
 	if err := dataContext.Add("Message", message); err != nil {
-		return nil, fmt.Errorf("failed to add message to data context: %v", err)
+		return nil, &DataContextError{Key: "Message", Err: err}
+	}
+	if err := dataContext.Add("World", mission.world); err != nil {
+		return nil, &DataContextError{Key: "World", Err: err}
+	}
+	if err := dataContext.Add("History", mission.history); err != nil {
+		return nil, &DataContextError{Key: "History", Err: err}
+	}
+	if err := dataContext.Add("Facts", mission.facts); err != nil {
+		return nil, &DataContextError{Key: "Facts", Err: err}
+	}
+	if err := dataContext.Add("ActionLog", mission.actions); err != nil {
+		return nil, &DataContextError{Key: "ActionLog", Err: err}
 	}
 	if err := dataContext.Add("Actions", actionCollector); err != nil {
-		return nil, fmt.Errorf("failed to add action collector to data context: %v", err)
+		return nil, &DataContextError{Key: "Actions", Err: err}
 	}
 	
-	// Set max cycle based on configuration
-	re.engine.MaxCycle = re.maxCycles
-	
-	// Execute rules - ignore max cycle error
-	err := re.engine.Execute(dataContext, kb)
-	if err != nil {
-		fmt.Printf("Rule execution warning: %v\n", err)
+	// Execute rules, keeping the partial result if execution stops early
+	result, err := re.execute(ContextSingle, dataContext, actionCollector)
+	if result == nil {
+		return nil, err
 	}
-	
-	actions := actionCollector.GetActions()
-	fmt.Printf("Generated %d actions\n", len(actions))
-	for i, action := range actions {
-		fmt.Printf("Action %d: Type=%s, SubType=%s, Zone=%s\n", i, action.Type, action.SubType, action.Zone)
+	now := mission.queue.clock()
+	mission.limiter.apply(result, now)
+	mission.track(result, now)
+	mission.queue.schedule(result)
+	if err != nil {
+		fmt.Fprintf(re.out, "Rule execution stopped: %v\n", err)
 	}
 	
-	return actions, nil
+	re.logResult(result)
+	return result, err
 }
 
-// ProcessMessages processes multiple DCS messages through the rules engine
-func (re *RuleEngine) ProcessMessages(messages []*models.Message) ([]models.Action, error) {
-	fmt.Printf("Processing %d messages\n", len(messages))
+// EvaluateMessages processes multiple DCS messages through the rules engine
+// and returns the actions together with the rules that produced them.
+// Messages derived from patterns the batch completed join the batch.
+// Errors are reported as for EvaluateMessage.
+func (re *RuleEngine) EvaluateMessages(messages []*models.Message) (*EvaluationResult, error) {
+	fmt.Fprintf(re.out, "Processing %d messages\n", len(messages))
 	
+	// Record the events in their missions' world state and history before the
+	// rules look at them. The batch sees the state of its first message's mission.
+	var mission *missionState
+	var derived []*models.Message
 	for i, msg := range messages {
-		fmt.Printf("Message %d: Event=%s, Zone=%s\n", i, msg.Event, msg.Zone)
+		re.zones.ResolveZones(msg)
+		fmt.Fprintf(re.out, "Message %d: Event=%s, Zone=%s\n", i, msg.Event, msg.Zone)
+		m, d := re.record(msg)
+		if mission == nil {
+			mission = m
+		}
+		derived = append(derived, d...)
+	}
+	if mission == nil {
+		mission = re.missions.get(models.DefaultMissionID)
 	}
 	
 	// Create a message collection
@@ -154,36 +493,74 @@ func (re *RuleEngine) ProcessMessages(messages []*models.Message) ([]models.Acti
 	for _, msg := range messages {
 		messageCollection.AddMessage(msg)
 	}
-	
-	// Get the knowledge base
-	kb := re.knowledgeLibrary.GetKnowledgeBase(KnowledgeBaseName, KnowledgeBaseVersion)
+	for _, msg := range derived {
+		messageCollection.AddMessage(msg)
+	}
 	
 	// Create an ActionCollector to store actions
-	actionCollector := models.NewActionCollector()
+	actionCollector := models.NewActionCollectorWithLog(mission.actions)
 	
 	// Create data context
 	dataContext := ast.NewDataContext()
 	if err := dataContext.Add("Messages", messageCollection); err != nil {
-		return nil, fmt.Errorf("failed to add message collection to data context: %v", err)
+		return nil, &DataContextError{Key: "Messages", Err: err}
+	}
+	if err := dataContext.Add("World", mission.world); err != nil {
+		return nil, &DataContextError{Key: "World", Err: err}
+	}
+	if err := dataContext.Add("History", mission.history); err != nil {
+		return nil, &DataContextError{Key: "History", Err: err}
+	}
+	if err := dataContext.Add("Facts", mission.facts); err != nil {
+		return nil, &DataContextError{Key: "Facts", Err: err}
+	}
+	if err := dataContext.Add("ActionLog", mission.actions); err != nil {
+		return nil, &DataContextError{Key: "ActionLog", Err: err}
 	}
 	if err := dataContext.Add("Actions", actionCollector); err != nil {
-		return nil, fmt.Errorf("failed to add action collector to data context: %v", err)
+		return nil, &DataContextError{Key: "Actions", Err: err}
 	}
 	
-	// Set max cycle based on configuration
-	re.engine.MaxCycle = re.maxCycles
-	
-	// Execute rules - ignore max cycle error
-	err := re.engine.Execute(dataContext, kb)
+	// Execute rules, keeping the partial result if execution stops early
+	result, err := re.execute(ContextBatch, dataContext, actionCollector)
+	if result == nil {
+		return nil, err
+	}
+	now := mission.queue.clock()
+	mission.limiter.apply(result, now)
+	mission.track(result, now)
+	mission.queue.schedule(result)
+	result.Due = mission.due()
 	if err != nil {
-		fmt.Printf("Rule execution warning: %v\n", err)
+		fmt.Fprintf(re.out, "Rule execution stopped: %v\n", err)
 	}
 	
-	actions := actionCollector.GetActions()
-	fmt.Printf("Generated %d actions\n", len(actions))
-	for i, action := range actions {
-		fmt.Printf("Action %d: Type=%s, SubType=%s, Zone=%s\n", i, action.Type, action.SubType, action.Zone)
+	re.logResult(result)
+	return result, err
+}
+
+// Output returns the writer the engine reports on
+func (re *RuleEngine) Output() io.Writer {
+	return re.out
+}
+
+// logResult reports the actions of an evaluation, the rules that produced
+// them and the actions that were rejected, suppressed or scheduled
+func (re *RuleEngine) logResult(result *EvaluationResult) {
+	for _, rejected := range result.Rejected {
+		fmt.Fprintf(re.out, "Dropped invalid %s action from rule %s: %s\n", rejected.Action.Type, rejected.Rule, strings.Join(rejected.Problems, "; "))
+	}
+	for _, suppressed := range result.Suppressed {
+		fmt.Fprintf(re.out, "Suppressed %s action from rule %s: %s\n", suppressed.Action.Type, suppressed.Rule, suppressed.Reason)
+	}
+	for _, scheduled := range result.Scheduled {
+		fmt.Fprintf(re.out, "Scheduled %s action %s from rule %s, due at %d\n", scheduled.Action.Type, scheduled.ID, scheduled.Rule, scheduled.DueAt)
+	}
+	fmt.Fprintf(re.out, "Generated %d actions\n", len(result.Actions))
+	for _, firing := range result.Trace {
+		fmt.Fprintf(re.out, "Rule %s fired in cycle %d, added %d actions\n", firing.Rule, firing.Cycle, len(firing.Actions))
+	}
+	for i, action := range result.Actions {
+		fmt.Fprintf(re.out, "Action %d: Type=%s, SubType=%s, Zone=%s, Rule=%s\n", i, action.Type, action.SubType, action.Zone, action.Rule)
 	}
-	
-	return actions, nil
 }
@@ -0,0 +1,302 @@
+// internal/rules/rule_engine_test.go
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bass4/dcs-ice/internal/config"
+	"github.com/bass4/dcs-ice/pkg/models"
+)
+
+const testRules = `
+rule DetectedInAlpha "Enemy detected in ALPHA" salience 10 {
+    when
+        Message.Event == "unit_detected" && Message.Zone == "ALPHA"
+    then
+        Actions.AddAlertAction("alert", "red", "Enemy in ALPHA");
+        Retract("DetectedInAlpha");
+}
+
+rule DestroyedInBravo "Unit destroyed in BRAVO" {
+    when
+        Message.Event == "unit_destroyed" && Message.Zone == "BRAVO"
+    then
+        Actions.AddSpawnAction("reinforcement", "BRAVO", "T-72", "2");
+        Retract("DestroyedInBravo");
+}
+
+rule ManyDetections "Several detections in one batch" {
+    when
+        Messages.CountMessagesByEvent("unit_detected") > 1
+    then
+        Actions.AddAlertAction("alert", "yellow", "Several detections");
+        Retract("ManyDetections");
+}
+`
+
+func init() {
+	SetGruleLogger(io.Discard, "error")
+}
+
+// newTestEngine writes rules to a temporary directory and loads them
+func newTestEngine(t testing.TB, grl string) (*RuleEngine, string) {
+	t.Helper()
+	return newTestEngineWithConfig(t, grl, &config.Config{MaxCycles: 10})
+}
+
+// newTestEngineWithConfig is newTestEngine with more configuration
+func newTestEngineWithConfig(t testing.TB, grl string, cfg *config.Config) (*RuleEngine, string) {
+	t.Helper()
+	dir := t.TempDir()
+	file := filepath.Join(dir, "rules.grl")
+	if err := os.WriteFile(file, []byte(grl), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.RulesDirs = []string{dir}
+	ruleEngine, err := NewRuleEngineWithOutput(cfg, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ruleEngine, file
+}
+
+func testMessage(event, zone string) *models.Message {
+	message := models.NewMessage(event)
+	message.Zone = zone
+	return message
+}
+
+func TestEvaluateMessage(t *testing.T) {
+	ruleEngine, _ := newTestEngine(t, testRules)
+
+	tests := []struct {
+		event, zone string
+		rules       []string
+	}{
+		{"unit_detected", "ALPHA", []string{"DetectedInAlpha"}},
+		{"unit_destroyed", "BRAVO", []string{"DestroyedInBravo"}},
+		{"unit_destroyed", "ALPHA", nil},
+	}
+
+	for _, tt := range tests {
+		result, err := ruleEngine.EvaluateMessage(testMessage(tt.event, tt.zone))
+		if err != nil {
+			t.Fatalf("%s in %s: %v", tt.event, tt.zone, err)
+		}
+		if fmt.Sprint(result.MatchedRules) != fmt.Sprint(tt.rules) {
+			t.Errorf("%s in %s: matched %v, want %v", tt.event, tt.zone, result.MatchedRules, tt.rules)
+		}
+		if len(result.Actions) != len(tt.rules) {
+			t.Errorf("%s in %s: got %d actions, want %d", tt.event, tt.zone, len(result.Actions), len(tt.rules))
+		}
+	}
+}
+
+// TestConcurrentEvaluationAndReload evaluates events and batches from several
+// goroutines while the rules are reloaded. Run it with -race.
+func TestConcurrentEvaluationAndReload(t *testing.T) {
+	ruleEngine, file := newTestEngine(t, testRules)
+
+	const workers, events = 8, 50
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*events)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < events; i++ {
+				if w%2 == 0 {
+					result, err := ruleEngine.EvaluateMessage(testMessage("unit_detected", "ALPHA"))
+					if err != nil {
+						errs <- err
+					} else if len(result.Actions) != 1 {
+						errs <- fmt.Errorf("single event: got %d actions, want 1", len(result.Actions))
+					}
+					continue
+				}
+
+				batch := []*models.Message{testMessage("unit_detected", "ALPHA"), testMessage("unit_detected", "BRAVO")}
+				result, err := ruleEngine.EvaluateMessages(batch)
+				if err != nil {
+					errs <- err
+				} else if len(result.Actions) != 1 {
+					errs <- fmt.Errorf("batch: got %d actions, want 1", len(result.Actions))
+				}
+			}
+		}(w)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			// Rewrite the file so every reload compiles a new rule set
+			grl := fmt.Sprintf("%s\n// reload %d\n", testRules, i)
+			if err := os.WriteFile(file, []byte(grl), 0o644); err != nil {
+				errs <- err
+				return
+			}
+			if err := ruleEngine.ReloadRules(); err != nil {
+				errs <- err
+			}
+		}
+	}()
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if got := ruleEngine.Version(); got != 11 {
+		t.Errorf("rule set version %d, want 11", got)
+	}
+}
+
+func BenchmarkEvaluate(b *testing.B) {
+	ruleEngine, _ := newTestEngine(b, testRules)
+	message := testMessage("unit_detected", "ALPHA")
+
+	b.Run("Serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := ruleEngine.EvaluateMessage(message); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Parallel", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if _, err := ruleEngine.EvaluateMessage(message); err != nil {
+					b.Error(err)
+					return
+				}
+			}
+		})
+	})
+}
+
+func TestDerivedMessagesEvaluatedAfterError(t *testing.T) {
+	dir := t.TempDir()
+	grl := `
+rule Looping "never retracts" {
+    when
+        Message.Event == "unit_destroyed"
+    then
+        Actions.AddAlertAction("loop", "yellow", "Looping");
+}
+
+rule OnStrike "derived event" {
+    when
+        Message.Event == "strike"
+    then
+        Actions.AddAlertAction("strike", "red", "Strike");
+        Retract("OnStrike");
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "rules.grl"), []byte(grl), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		RulesDirs: []string{dir},
+		MaxCycles: 5,
+		Patterns:  []config.PatternConfig{{Name: "strike", Steps: []config.PatternStepConfig{{Event: "unit_destroyed"}}}},
+	}
+	ruleEngine, err := NewRuleEngineWithOutput(cfg, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ruleEngine.EvaluateMessage(testMessage("unit_destroyed", "BRAVO"))
+	var cycleErr *CycleExhaustedError
+	if !errors.As(err, &cycleErr) || cycleErr.Rule != "Looping" {
+		t.Fatalf("got error %v, want the cycle exhaustion of Looping", err)
+	}
+	if result == nil || !containsString(result.MatchedRules, "OnStrike") {
+		t.Fatalf("derived message was not evaluated: %+v", result)
+	}
+	// Looping adds its alert in each of the 5 cycles
+	if len(result.Actions) != 6 {
+		t.Errorf("got %d actions, want 6", len(result.Actions))
+	}
+}
+
+func TestRuleFiringSeveralTimes(t *testing.T) {
+	ruleEngine, _ := newTestEngine(t, `
+rule CountUp "Fires once per unit until three were counted" {
+    when
+        Message.Event == "tick" && Message.Count < 3
+    then
+        Actions.AddAlertAction("tick", "yellow", "Tick");
+        Actions.AddSmokeAction("ALPHA", "red");
+        Actions.DelayLast(60);
+        Message.Count = Message.Count + 1;
+}
+
+rule AlsoTicks "Shares a then call with CountUp" {
+    when
+        Message.Event == "tick" && Message.Count == 3
+    then
+        Actions.AddAlertAction("tick", "yellow", "Tick");
+        Retract("AlsoTicks");
+}
+`)
+
+	result, err := ruleEngine.EvaluateMessage(testMessage("tick", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Trace) != 4 {
+		t.Fatalf("got %d firings, want 4: %+v", len(result.Trace), result.Trace)
+	}
+	for i, firing := range result.Trace {
+		want := 1
+		if firing.Rule == "CountUp" {
+			want = 2
+		}
+		if got := len(firing.Actions) + len(firing.Scheduled); got != want {
+			t.Errorf("firing %d of %s added %d actions, want %d", i, firing.Rule, got, want)
+		}
+	}
+	if len(result.Actions) != 4 || len(result.Scheduled) != 3 {
+		t.Errorf("got %d actions and %d scheduled, want 4 and 3", len(result.Actions), len(result.Scheduled))
+	}
+}
+
+func TestLoadRulesRejectsDuplicateNamesAcrossFiles(t *testing.T) {
+	ruleEngine, file := newTestEngine(t, testRules)
+	other := filepath.Join(filepath.Dir(file), "zz.grl")
+	if err := os.WriteFile(other, []byte(`
+rule DestroyedInBravo "Same name as in rules.grl" {
+    when
+        Message.Event == "unit_destroyed"
+    then
+        Retract("DestroyedInBravo");
+}
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var reloadErr *ReloadError
+	if err := ruleEngine.ReloadRules(); !errors.As(err, &reloadErr) {
+		t.Fatalf("got error %v, want a *ReloadError", err)
+	}
+	if len(reloadErr.Files) != 1 || reloadErr.Files[0].File != other {
+		t.Fatalf("got failing files %+v, want %s", reloadErr.Files, other)
+	}
+	if got := strings.Join(reloadErr.Files[0].Errors, "\n"); !strings.Contains(got, "duplicate rule name DestroyedInBravo, first declared at "+file+":") {
+		t.Errorf("got errors %q", got)
+	}
+	if got := ruleEngine.Version(); got != 1 {
+		t.Errorf("rule set version %d after the failed reload, want 1", got)
+	}
+}
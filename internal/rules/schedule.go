@@ -0,0 +1,210 @@
+// internal/rules/schedule.go
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bass4/dcs-ice/pkg/models"
+)
+
+// ScheduledAction is an action a rule delayed to a later mission time
+type ScheduledAction struct {
+	ID          string        `json:"id"` // ID of the action
+	MissionID   string        `json:"mission_id"`
+	Action      models.Action `json:"action"`
+	Rule        string        `json:"rule"`
+	ScheduledAt int64         `json:"scheduled_at"` // Mission time the rule fired
+	DueAt       int64         `json:"due_at"`       // Mission time the action is delivered
+}
+
+// actionQueue holds the scheduled actions of one mission until they are due.
+//
+// Mission time only advances with event timestamps, so between events it is
+// extrapolated with the wall clock from the latest timestamp seen.
+type actionQueue struct {
+	mu        sync.Mutex
+	missionID string
+	pending   []*ScheduledAction // Sorted by DueAt
+	anchor    int64              // Latest mission time reported by an event
+	anchorAt  time.Time          // Wall time the anchor was set; zero before the first event
+}
+
+func newActionQueue(missionID string) *actionQueue {
+	return &actionQueue{missionID: missionID}
+}
+
+// observe advances the mission clock with the time of an event. Timestamped
+// events re-anchor the clock; the first event starts it either way.
+func (q *actionQueue) observe(missionTime int64, timestamped bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.anchorAt.IsZero() || (timestamped && missionTime >= q.anchor) {
+		q.anchor = missionTime
+		q.anchorAt = time.Now()
+	}
+}
+
+// now returns the current mission time. Callers hold the lock.
+func (q *actionQueue) now() int64 {
+	if q.anchorAt.IsZero() {
+		return q.anchor
+	}
+	return q.anchor + int64(time.Since(q.anchorAt)/time.Second)
+}
+
+// schedule moves the delayed actions of a result into the queue and records
+// them as scheduled, both on the result and on the rule firing that produced them
+func (q *actionQueue) schedule(result *EvaluationResult) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	actions := make([]models.Action, 0, len(result.Actions))
+	for i := range result.Trace {
+		firing := &result.Trace[i]
+		kept := make([]models.Action, 0, len(firing.Actions))
+		for _, action := range firing.Actions {
+			if action.Delay <= 0 {
+				kept = append(kept, action)
+				continue
+			}
+			scheduled := &ScheduledAction{
+				ID:          action.ID,
+				MissionID:   q.missionID,
+				Action:      action,
+				Rule:        action.Rule,
+				ScheduledAt: now,
+				DueAt:       now + action.Delay,
+			}
+			q.insert(scheduled)
+			firing.Scheduled = append(firing.Scheduled, *scheduled)
+			result.Scheduled = append(result.Scheduled, *scheduled)
+		}
+		firing.Actions = kept
+		actions = append(actions, kept...)
+	}
+	result.Actions = actions
+}
+
+// add queues an action that is not part of an evaluation result
+func (q *actionQueue) add(scheduled *ScheduledAction) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.insert(scheduled)
+}
+
+// insert keeps the pending actions sorted by due time. Callers hold the lock.
+func (q *actionQueue) insert(scheduled *ScheduledAction) {
+	q.pending = append(q.pending, scheduled)
+	sort.SliceStable(q.pending, func(i, j int) bool { return q.pending[i].DueAt < q.pending[j].DueAt })
+}
+
+// clock returns the current mission time
+func (q *actionQueue) clock() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.now()
+}
+
+// due removes and returns the actions whose time has come
+func (q *actionQueue) due() []ScheduledAction {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	n := 0
+	for n < len(q.pending) && q.pending[n].DueAt <= now {
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+
+	due := make([]ScheduledAction, 0, n)
+	for _, scheduled := range q.pending[:n] {
+		due = append(due, *scheduled)
+	}
+	q.pending = append(q.pending[:0], q.pending[n:]...)
+	return due
+}
+
+// size returns the number of pending actions
+func (q *actionQueue) size() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// list returns a copy of the pending actions, soonest first
+func (q *actionQueue) list() []ScheduledAction {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	pending := make([]ScheduledAction, 0, len(q.pending))
+	for _, scheduled := range q.pending {
+		pending = append(pending, *scheduled)
+	}
+	return pending
+}
+
+// cancel removes a pending action
+func (q *actionQueue) cancel(id string) (ScheduledAction, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, scheduled := range q.pending {
+		if scheduled.ID == id {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			return *scheduled, true
+		}
+	}
+	return ScheduledAction{}, false
+}
+
+// ScheduledActions returns the pending actions of a mission, soonest first.
+// An empty ID selects the default mission.
+func (re *RuleEngine) ScheduledActions(missionID string) []ScheduledAction {
+	mission, ok := re.missions.lookup(missionID)
+	if !ok {
+		return []ScheduledAction{}
+	}
+	return mission.queue.list()
+}
+
+// CancelScheduledAction removes a pending action of a mission before it is delivered
+func (re *RuleEngine) CancelScheduledAction(missionID, id string) (ScheduledAction, bool) {
+	mission, ok := re.missions.lookup(missionID)
+	if !ok {
+		return ScheduledAction{}, false
+	}
+	scheduled, ok := mission.queue.cancel(id)
+	if ok {
+		mission.actions.Update(id, models.ActionCancelled, "", "", mission.queue.clock())
+	}
+	return scheduled, ok
+}
+
+// DueActions removes and returns the scheduled actions of a mission that are
+// due. Each action is returned by exactly one call.
+func (re *RuleEngine) DueActions(missionID string) []ScheduledAction {
+	due := re.missions.get(missionID).due()
+	for _, scheduled := range due {
+		fmt.Fprintf(re.out, "Delivering scheduled %s action %s from rule %s\n", scheduled.Action.Type, scheduled.ID, scheduled.Rule)
+	}
+	return due
+}
+
+// RequeueScheduledActions puts due actions that could not be delivered back in
+// their mission's queue, still scheduled, to be handed out again
+func (re *RuleEngine) RequeueScheduledActions(missionID string, due []ScheduledAction) {
+	mission := re.missions.get(missionID)
+	now := mission.queue.clock()
+	for i := range due {
+		scheduled := due[i]
+		mission.queue.add(&scheduled)
+		mission.actions.Update(scheduled.ID, models.ActionScheduled, "", "", now)
+	}
+}
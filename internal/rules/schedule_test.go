@@ -0,0 +1,35 @@
+// internal/rules/schedule_test.go
+package rules
+
+import (
+	"testing"
+
+	"github.com/bass4/dcs-ice/pkg/models"
+)
+
+func TestRequeueScheduledActions(t *testing.T) {
+	ruleEngine, _ := newTestEngine(t, testRules)
+	mission := ruleEngine.missions.get("")
+	mission.queue.observe(100, true)
+
+	action := models.Action{ID: "act-1", Type: "spawn", Zone: "BRAVO", Delay: 60}
+	mission.actions.Record(action, models.ActionScheduled, 40)
+	mission.queue.add(&ScheduledAction{ID: "act-1", Action: action, ScheduledAt: 40, DueAt: 100})
+
+	due := ruleEngine.DueActions("")
+	if len(due) != 1 {
+		t.Fatalf("%d actions due, want 1", len(due))
+	}
+	if record, _ := ruleEngine.ActionLog("").Get("act-1"); record.Status != models.ActionSent {
+		t.Fatalf("due action is %s, want sent", record.Status)
+	}
+
+	// The push failed: the action is scheduled again and handed out with the next check
+	ruleEngine.RequeueScheduledActions("", due)
+	if record, _ := ruleEngine.ActionLog("").Get("act-1"); record.Status != models.ActionScheduled {
+		t.Errorf("requeued action is %s, want scheduled", record.Status)
+	}
+	if again := ruleEngine.DueActions(""); len(again) != 1 || again[0].ID != "act-1" {
+		t.Errorf("requeued action not handed out again: %+v", again)
+	}
+}
@@ -0,0 +1,214 @@
+// internal/rules/trace.go
+package rules
+
+import (
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+
+	"github.com/bass4/dcs-ice/pkg/models"
+)
+
+// RuleFiring records one execution of a rule during an evaluation
+type RuleFiring struct {
+	Rule    string          `json:"rule"`
+	Cycle   uint64          `json:"cycle"`
+	Actions []models.Action `json:"actions"`
+	Derived string          `json:"derived,omitempty"` // Event type of the derived message the rule fired for, if any
+
+	Suppressed []SuppressedAction `json:"suppressed,omitempty"` // Actions held back by a limit or deduplication
+	Scheduled  []ScheduledAction  `json:"scheduled,omitempty"`  // Actions delayed to a later mission time
+}
+
+// EvaluationResult is the outcome of evaluating one or more messages
+type EvaluationResult struct {
+	Actions        []models.Action    `json:"actions"`
+	Rejected       []RejectedAction   `json:"rejected,omitempty"`   // Actions dropped for not matching their schema
+	Suppressed     []SuppressedAction `json:"suppressed,omitempty"` // Actions held back by a limit or deduplication
+	Scheduled      []ScheduledAction  `json:"scheduled,omitempty"`  // Actions delayed to a later mission time
+	Due            []ScheduledAction  `json:"due,omitempty"`        // Earlier scheduled actions that came due, delivered with this result
+	MatchedRules   []string           `json:"matched_rules"`        // Distinct rules that fired, in firing order
+	Trace          []RuleFiring       `json:"trace"`                // Every rule execution, in order
+	Cycles         uint64             `json:"cycles"`
+	RuleSetVersion uint64             `json:"rule_set_version"`
+}
+
+// traceListener is a grule engine listener that records which rule fired in
+// which cycle and which actions each firing added.
+type traceListener struct {
+	collector *models.ActionCollector
+	firings   []RuleFiring
+	starts    []int // index of the first action added by each firing
+	cycles    uint64
+
+	// The cycle in progress, used to tell why the engine stopped
+	began      uint64
+	evaluated  map[string]bool  // Rules whose when scope was evaluated
+	candidates []*ast.RuleEntry // Rules eligible to fire, in evaluation order
+	executing  bool             // Whether a rule was picked to fire
+}
+
+func newTraceListener(collector *models.ActionCollector) *traceListener {
+	return &traceListener{collector: collector}
+}
+
+// EvaluateRuleEntry is called for every rule whose when scope was evaluated
+func (t *traceListener) EvaluateRuleEntry(cycle uint64, entry *ast.RuleEntry, candidate bool) {
+	t.evaluated[entry.RuleName] = true
+	if candidate {
+		t.candidates = append(t.candidates, entry)
+	}
+}
+
+// ExecuteRuleEntry is called right before a rule's then scope is executed
+func (t *traceListener) ExecuteRuleEntry(cycle uint64, entry *ast.RuleEntry) {
+	forgetThenScope(entry)
+
+	t.executing = true
+	t.collector.SetRule(entry.RuleName)
+	t.firings = append(t.firings, RuleFiring{Rule: entry.RuleName, Cycle: cycle})
+	t.starts = append(t.starts, len(t.collector.GetActions()))
+	t.cycles = cycle
+}
+
+// forgetThenScope clears the results grule memoized in a rule's then scope,
+// down to the arguments of every call, so that each firing runs all of its
+// calls with fresh arguments. grule memoizes expressions by their text across
+// rules and cycles, so otherwise a call such as Actions.DelayLast(600) would
+// run once per evaluation. The AST belongs to the knowledge base instance of
+// this evaluation alone.
+func forgetThenScope(entry *ast.RuleEntry) {
+	if entry.ThenScope == nil || entry.ThenScope.ThenExpressionList == nil {
+		return
+	}
+	for _, expression := range entry.ThenScope.ThenExpressionList.ThenExpressions {
+		if expression.Assignment != nil {
+			forgetVariable(expression.Assignment.Variable)
+			forgetExpression(expression.Assignment.Expression)
+		}
+		forgetAtom(expression.ExpressionAtom)
+	}
+}
+
+func forgetExpression(expression *ast.Expression) {
+	if expression == nil {
+		return
+	}
+	expression.Evaluated = false
+	forgetExpression(expression.LeftExpression)
+	forgetExpression(expression.RightExpression)
+	forgetExpression(expression.SingleExpression)
+	forgetAtom(expression.ExpressionAtom)
+}
+
+func forgetAtom(atom *ast.ExpressionAtom) {
+	if atom == nil {
+		return
+	}
+	atom.Evaluated = false
+	forgetAtom(atom.ExpressionAtom)
+	forgetVariable(atom.Variable)
+	if atom.FunctionCall != nil && atom.FunctionCall.ArgumentList != nil {
+		for _, argument := range atom.FunctionCall.ArgumentList.Arguments {
+			forgetExpression(argument)
+		}
+	}
+	if atom.ArrayMapSelector != nil {
+		forgetExpression(atom.ArrayMapSelector.Expression)
+	}
+}
+
+func forgetVariable(variable *ast.Variable) {
+	for ; variable != nil; variable = variable.Variable {
+		if variable.ArrayMapSelector != nil {
+			forgetExpression(variable.ArrayMapSelector.Expression)
+		}
+	}
+}
+
+// BeginCycle is called at the start of every evaluation cycle
+func (t *traceListener) BeginCycle(cycle uint64) {
+	t.began = cycle
+	t.evaluated = make(map[string]bool)
+	t.candidates = nil
+	t.executing = false
+}
+
+// lastRule returns the rule that fired most recently
+func (t *traceListener) lastRule() string {
+	if len(t.firings) == 0 {
+		return ""
+	}
+	return t.firings[len(t.firings)-1].Rule
+}
+
+// nextRule returns the candidate of the current cycle grule would fire next:
+// the first one with the highest salience
+func (t *traceListener) nextRule() string {
+	if len(t.candidates) == 0 {
+		return ""
+	}
+	next := t.candidates[0]
+	for _, candidate := range t.candidates[1:] {
+		if candidate.Salience > next.Salience {
+			next = candidate
+		}
+	}
+	return next.RuleName
+}
+
+// result assembles the evaluation result once the engine has finished
+func (t *traceListener) result() *EvaluationResult {
+	actions := t.collector.GetActions()
+
+	result := &EvaluationResult{
+		Actions:      actions,
+		MatchedRules: make([]string, 0),
+		Trace:        t.firings,
+		Cycles:       t.cycles,
+	}
+
+	seen := make(map[string]bool)
+	for i := range t.firings {
+		end := len(actions)
+		if i+1 < len(t.starts) {
+			end = t.starts[i+1]
+		}
+		t.firings[i].Actions = actions[t.starts[i]:end]
+
+		if !seen[t.firings[i].Rule] {
+			seen[t.firings[i].Rule] = true
+			result.MatchedRules = append(result.MatchedRules, t.firings[i].Rule)
+		}
+	}
+	if result.Trace == nil {
+		result.Trace = make([]RuleFiring, 0)
+	}
+
+	return result
+}
+
+// merge appends the result of evaluating a derived message
+func (r *EvaluationResult) merge(derived *EvaluationResult, event string) {
+	r.Actions = append(r.Actions, derived.Actions...)
+	r.Rejected = append(r.Rejected, derived.Rejected...)
+	r.Suppressed = append(r.Suppressed, derived.Suppressed...)
+	r.Scheduled = append(r.Scheduled, derived.Scheduled...)
+	for _, rule := range derived.MatchedRules {
+		if !containsString(r.MatchedRules, rule) {
+			r.MatchedRules = append(r.MatchedRules, rule)
+		}
+	}
+	for _, firing := range derived.Trace {
+		firing.Derived = event
+		r.Trace = append(r.Trace, firing)
+	}
+	r.Cycles += derived.Cycles
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+// internal/rules/watcher.go
+package rules
+
+import (
+	"context"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// watchPollInterval is how often the watched rule paths are scanned for changes
+const watchPollInterval = 250 * time.Millisecond
+
+// fileState is the part of a file's metadata used to detect changes
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// RuleWatcher polls the engine's rule directories and files and triggers a
+// reload once a burst of changes has settled.
+type RuleWatcher struct {
+	engine   *RuleEngine
+	debounce time.Duration
+}
+
+// NewRuleWatcher creates a watcher that reloads ruleEngine when its rule files
+// are added, changed or removed. A reload happens once no further change has
+// been seen for the debounce duration.
+func NewRuleWatcher(ruleEngine *RuleEngine, debounce time.Duration) *RuleWatcher {
+	return &RuleWatcher{
+		engine:   ruleEngine,
+		debounce: debounce,
+	}
+}
+
+// Run watches the rule files until ctx is cancelled
+func (w *RuleWatcher) Run(ctx context.Context) {
+	log.Printf("Watching rule files for changes (debounce %s)", w.debounce)
+
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	last, err := w.snapshot()
+	if err != nil {
+		log.Printf("Rule watcher: %v", err)
+	}
+	var pending []string
+	var lastChange time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			// A directory that cannot be read says nothing about its files, so
+			// skip the tick rather than treat every file as removed
+			current, err := w.snapshot()
+			if err != nil {
+				log.Printf("Rule watcher: %v", err)
+				continue
+			}
+			if last == nil {
+				last = current
+				continue
+			}
+
+			if changes := diffSnapshots(last, current); len(changes) > 0 {
+				pending = mergeChanges(pending, changes)
+				lastChange = now
+				last = current
+			}
+
+			if len(pending) > 0 && now.Sub(lastChange) >= w.debounce {
+				w.reload(pending)
+				pending = nil
+			}
+		}
+	}
+}
+
+// reload performs a safe reload and logs its outcome
+func (w *RuleWatcher) reload(changes []string) {
+	log.Printf("Rule files changed: %s; reloading", strings.Join(changes, ", "))
+
+	if err := w.engine.ReloadRules(); err != nil {
+		log.Printf("Rule reload failed, keeping rule set version %d: %v", w.engine.Version(), err)
+		return
+	}
+
+	log.Printf("Rule reload succeeded, active rule set version %d", w.engine.Version())
+}
+
+// snapshot records the state of every rule file currently present. It
+// returns nil and the error if a rules directory cannot be read.
+func (w *RuleWatcher) snapshot() (map[string]fileState, error) {
+	files, err := collectRuleFiles(w.engine.rulesDirs, w.engine.rulesFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[string]fileState, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			// Explicitly configured files may have been removed
+			continue
+		}
+		states[file] = fileState{modTime: info.ModTime(), size: info.Size()}
+	}
+	return states, nil
+}
+
+// diffSnapshots describes the differences between two snapshots, e.g. "added a.grl"
+func diffSnapshots(before, after map[string]fileState) []string {
+	var changes []string
+	for file, state := range after {
+		previous, existed := before[file]
+		if !existed {
+			changes = append(changes, "added "+file)
+		} else if previous != state {
+			changes = append(changes, "changed "+file)
+		}
+	}
+	for file := range before {
+		if _, exists := after[file]; !exists {
+			changes = append(changes, "removed "+file)
+		}
+	}
+	sort.Strings(changes)
+	return changes
+}
+
+// mergeChanges appends changes that are not already pending
+func mergeChanges(pending, changes []string) []string {
+	for _, change := range changes {
+		seen := false
+		for _, p := range pending {
+			if p == change {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			pending = append(pending, change)
+		}
+	}
+	return pending
+}
@@ -0,0 +1,28 @@
+// internal/rules/zones.go
+package rules
+
+import (
+	"github.com/bass4/dcs-ice/internal/config"
+	"github.com/bass4/dcs-ice/pkg/models"
+)
+
+// newZoneRegistry builds the zone registry from the validated zone configuration
+func newZoneRegistry(zoneConfigs []config.ZoneConfig) *models.ZoneRegistry {
+	zones := make([]*models.Zone, 0, len(zoneConfigs))
+	for _, zc := range zoneConfigs {
+		zone := &models.Zone{
+			Name:        zc.Name,
+			MinAltitude: zc.MinAltitude,
+			MaxAltitude: zc.MaxAltitude,
+		}
+		if zc.Circle != nil {
+			zone.Center = &models.Position{X: zc.Circle.X, Z: zc.Circle.Z}
+			zone.Radius = zc.Circle.Radius
+		}
+		for _, point := range zc.Polygon {
+			zone.Polygon = append(zone.Polygon, models.Position{X: point.X, Z: point.Z})
+		}
+		zones = append(zones, zone)
+	}
+	return models.NewZoneRegistry(zones)
+}
@@ -0,0 +1,201 @@
+// internal/ruletest/ruletest.go
+package ruletest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/bass4/dcs-ice/internal/api"
+	"github.com/bass4/dcs-ice/internal/rules"
+)
+
+// Evaluation modes of a fixture
+const (
+	ModeSingle = "single" // Each event is evaluated on its own, like /api/dcs/event
+	ModeBatch  = "batch"  // All events are evaluated together, like /api/dcs/batch
+)
+
+// Fixture describes DCS events and the actions the rules are expected to return for them
+type Fixture struct {
+	Name            string          `json:"name"`
+	Mode            string          `json:"mode,omitempty"`
+	Events          []api.DCSEvent  `json:"events"`
+	ExpectedActions []api.DCSAction `json:"expected_actions"`
+	ExpectedStatus  string          `json:"expected_status,omitempty"` // Defaults to success
+	File            string          `json:"-"`
+}
+
+// Result is the outcome of running one fixture
+type Result struct {
+	Fixture        *Fixture
+	Passed         bool
+	Status         string
+	ExpectedStatus string
+	MatchedRules   []string
+	Missing        []string                // Expected actions that were not returned, as JSON
+	Unexpected     []string                // Returned actions that were not expected, as JSON
+	Rejected       []api.DCSRejectedAction // Actions dropped for not matching their schema
+	Err            error                   // Set if the fixture is invalid
+}
+
+// LoadFixtures reads every .json fixture file in dir. A file holds either a
+// single fixture object or an array of fixtures.
+func LoadFixtures(dir string) ([]*Fixture, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures directory %s: %v", dir, err)
+	}
+
+	var fixtures []*Fixture
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		loaded, err := LoadFixtureFile(path)
+		if err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, loaded...)
+	}
+
+	return fixtures, nil
+}
+
+// LoadFixtureFile reads the fixtures in a single file
+func LoadFixtureFile(path string) ([]*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixtures []*Fixture
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(data, &fixtures)
+	} else {
+		var fixture Fixture
+		err = json.Unmarshal(data, &fixture)
+		fixtures = []*Fixture{&fixture}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid fixture file %s: %v", path, err)
+	}
+
+	for i, fixture := range fixtures {
+		fixture.File = path
+		if fixture.Name == "" {
+			fixture.Name = fmt.Sprintf("%s#%d", filepath.Base(path), i+1)
+		}
+	}
+
+	return fixtures, nil
+}
+
+// Run evaluates a fixture's events through the rule engine and compares the
+// returned actions with the expected ones. The order of actions is ignored.
+// The world state of the fixture's missions is reset first, so the events of
+// a single-mode fixture build on each other but not on other fixtures.
+func Run(ruleEngine *rules.RuleEngine, fixture *Fixture) *Result {
+	result := &Result{Fixture: fixture, ExpectedStatus: fixture.ExpectedStatus}
+	if result.ExpectedStatus == "" {
+		result.ExpectedStatus = api.StatusSuccess
+	}
+
+	if len(fixture.Events) == 0 {
+		result.Err = fmt.Errorf("fixture has no events")
+		return result
+	}
+
+	// Every fixture starts from an empty world state
+	for _, event := range fixture.Events {
+		ruleEngine.ResetWorld(event.MissionID)
+	}
+
+	var actions []api.DCSAction
+	switch fixture.Mode {
+	case "", ModeSingle:
+		result.Status = api.StatusSuccess
+		for _, event := range fixture.Events {
+			response := api.ProcessEvent(ruleEngine, event, false)
+			actions = append(actions, response.Actions...)
+			result.MatchedRules = append(result.MatchedRules, response.MatchedRules...)
+			result.Rejected = append(result.Rejected, response.Rejected...)
+			if response.Status != api.StatusSuccess {
+				result.Status = response.Status
+			}
+		}
+
+	case ModeBatch:
+		response := api.ProcessBatch(ruleEngine, fixture.Events, false)
+		actions = response.Actions
+		result.MatchedRules = response.MatchedRules
+		result.Rejected = response.Rejected
+		result.Status = response.Status
+
+	default:
+		result.Err = fmt.Errorf("unknown mode %q, expected %q or %q", fixture.Mode, ModeSingle, ModeBatch)
+		return result
+	}
+
+	result.Missing, result.Unexpected = diffActions(fixture.ExpectedActions, actions)
+	result.Passed = result.Status == result.ExpectedStatus && len(result.Missing) == 0 && len(result.Unexpected) == 0
+	return result
+}
+
+// diffActions compares two action lists as multisets and returns the actions
+// only found in expected and those only found in actual
+func diffActions(expected, actual []api.DCSAction) (missing, unexpected []string) {
+	remaining := make(map[string]int)
+	for _, action := range actual {
+		remaining[canonicalAction(action)]++
+	}
+
+	for _, action := range expected {
+		key := canonicalAction(action)
+		if remaining[key] > 0 {
+			remaining[key]--
+			continue
+		}
+		missing = append(missing, key)
+	}
+
+	for _, action := range actual {
+		key := canonicalAction(action)
+		if remaining[key] > 0 {
+			remaining[key]--
+			unexpected = append(unexpected, key)
+		}
+	}
+
+	sort.Strings(missing)
+	sort.Strings(unexpected)
+	return missing, unexpected
+}
+
+// canonicalAction renders an action as JSON with sorted keys, so that numbers
+// and nested values compare the same whether they came from a fixture or a rule.
+// Action IDs are generated and left out.
+func canonicalAction(action api.DCSAction) string {
+	action.ID = ""
+	if action.Data == nil {
+		action.Data = make(map[string]interface{})
+	}
+
+	data, err := json.Marshal(action)
+	if err != nil {
+		return fmt.Sprintf("%+v", action)
+	}
+
+	// Round-trip through a generic value so both sides share number formatting
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return string(data)
+	}
+	data, _ = json.Marshal(generic)
+	return string(data)
+}
@@ -2,6 +2,7 @@ package models
 
 // Action represents an action to be performed as a result of rule evaluation
 type Action struct {
+    ID        string `json:"id,omitempty"` // Assigned when the action leaves the rule engine
     Type      string `json:"type"`
     SubType   string `json:"sub_type,omitempty"`
     Zone      string `json:"zone,omitempty"`
@@ -10,4 +11,85 @@ type Action struct {
     Level     string `json:"level,omitempty"`
     Message   string `json:"message,omitempty"`
     GroupName string `json:"group_name,omitempty"`
+    Rule      string `json:"rule,omitempty"`     // Rule that produced the action
+    Delay     int64  `json:"delay,omitempty"`    // Seconds of mission time to hold the action back
+    RetryOf   string `json:"retry_of,omitempty"` // ID of the failed action this one retries
+
+    // Params are passed to DCS unchanged in the action's data
+    Params map[string]interface{} `json:"params,omitempty"`
+
+    problems []string // Why the rule could not build the action as asked; such actions are rejected
+}
+
+// Problems returns why the rule could not build the action as asked
+func (a Action) Problems() []string {
+    return a.problems
+}
+
+// Retry returns a copy of the action to send again on behalf of a rule. The
+// copy gets its own ID when it leaves the rule engine.
+func (a Action) Retry(rule string) Action {
+    retry := a
+    retry.ID = ""
+    retry.RetryOf = a.ID
+    retry.Delay = 0
+    retry.Rule = rule
+    if a.Params != nil {
+        retry.Params = make(map[string]interface{}, len(a.Params))
+        for key, value := range a.Params {
+            retry.Params[key] = value
+        }
+    }
+    return retry
+}
+
+// Data returns the parameters sent to DCS with the action. A typed field that
+// is set takes precedence over Params of the same name.
+func (a Action) Data() map[string]interface{} {
+    data := make(map[string]interface{}, len(a.Params))
+
+    // Generic parameters pass through unless a typed field below is set
+    for key, value := range a.Params {
+        data[key] = value
+    }
+    set := func(key, value string) {
+        if _, fromParams := data[key]; value != "" || !fromParams {
+            data[key] = value
+        }
+    }
+
+    // Convert each action type to the appropriate DCS action format
+    switch a.Type {
+    case "spawn":
+        set("zone", a.Zone)
+        set("unit_type", a.UnitType)
+        set("count", a.Count)
+
+    case "alert":
+        set("level", a.Level)
+        set("message", a.Message)
+        if a.Zone != "" {
+            set("zone", a.Zone)
+        }
+
+    case "reinforce":
+        set("unit_type", a.UnitType)
+        set("group_name", a.GroupName)
+        set("zone", a.Zone)
+        set("count", a.Count)
+
+    case "despawn", "set_roe", "set_alarm_state":
+        set("group_name", a.GroupName)
+
+    case "message":
+        set("message", a.Message)
+        if a.GroupName != "" {
+            set("group_name", a.GroupName)
+        }
+
+    case "smoke", "illumination":
+        set("zone", a.Zone)
+    }
+
+    return data
 }
@@ -1,9 +1,18 @@
 // pkg/models/action_collector.go
 package models
 
+import (
+    "fmt"
+    "strconv"
+)
+
 // ActionCollector collects actions generated by rules
 type ActionCollector struct {
-    actions []Action
+    actions     []Action
+    rule        string
+    firingStart int             // Index of the first action added by the current rule firing
+    log         *ActionLog      // Mission's earlier actions, for RetryAction
+    retried     map[string]bool // Actions retried in this evaluation
 }
 
 // NewActionCollector creates a new action collector
@@ -13,6 +22,14 @@ func NewActionCollector() *ActionCollector {
     }
 }
 
+// NewActionCollectorWithLog creates an action collector that can retry the
+// actions recorded in log
+func NewActionCollectorWithLog(log *ActionLog) *ActionCollector {
+    ac := NewActionCollector()
+    ac.log = log
+    return ac
+}
+
 // AddSpawnAction adds a spawn action
 func (ac *ActionCollector) AddSpawnAction(actionType, zone, unitType, count string) {
     action := Action{
@@ -21,6 +38,7 @@ func (ac *ActionCollector) AddSpawnAction(actionType, zone, unitType, count stri
         Zone:     zone,
         UnitType: unitType,
         Count:    count,
+        Rule:     ac.rule,
     }
     ac.actions = append(ac.actions, action)
 }
@@ -32,10 +50,205 @@ func (ac *ActionCollector) AddAlertAction(actionType, level, message string) {
         SubType: actionType,
         Level:   level,
         Message: message,
+        Rule:    ac.rule,
+    }
+    ac.actions = append(ac.actions, action)
+}
+
+// AddReinforceAction adds units of a type to a group in a zone
+func (ac *ActionCollector) AddReinforceAction(groupName, zone, unitType string, count int64) {
+    ac.actions = append(ac.actions, Action{
+        Type:      "reinforce",
+        GroupName: groupName,
+        Zone:      zone,
+        UnitType:  unitType,
+        Count:     strconv.FormatInt(count, 10),
+        Rule:      ac.rule,
+    })
+}
+
+// AddDespawnAction removes a group silently
+func (ac *ActionCollector) AddDespawnAction(groupName string) {
+    ac.addGroupCommand("despawn", "remove", groupName, nil)
+}
+
+// AddDestroyGroupAction destroys every unit of a group, as if killed
+func (ac *ActionCollector) AddDestroyGroupAction(groupName string) {
+    ac.addGroupCommand("despawn", "destroy", groupName, nil)
+}
+
+// AddSetROEAction sets a group's rules of engagement: weapons_free,
+// open_fire, return_fire or weapon_hold
+func (ac *ActionCollector) AddSetROEAction(groupName, roe string) {
+    ac.addGroupCommand("set_roe", "", groupName, map[string]interface{}{"roe": roe})
+}
+
+// AddSetAlarmStateAction sets a ground group's alarm state: auto, green or red
+func (ac *ActionCollector) AddSetAlarmStateAction(groupName, state string) {
+    ac.addGroupCommand("set_alarm_state", "", groupName, map[string]interface{}{"state": state})
+}
+
+// addGroupCommand adds an action addressed to a group
+func (ac *ActionCollector) addGroupCommand(actionType, subType, groupName string, params map[string]interface{}) {
+    ac.actions = append(ac.actions, Action{
+        Type:      actionType,
+        SubType:   subType,
+        GroupName: groupName,
+        Params:    params,
+        Rule:      ac.rule,
+    })
+}
+
+// AddSetFlagAction sets a mission user flag
+func (ac *ActionCollector) AddSetFlagAction(flag string, value int64) {
+    ac.actions = append(ac.actions, Action{
+        Type:   "set_flag",
+        Params: map[string]interface{}{"flag": flag, "value": value},
+        Rule:   ac.rule,
+    })
+}
+
+// AddMessageToCoalitionAction shows a text message to a coalition (red, blue
+// or neutral) for a number of seconds
+func (ac *ActionCollector) AddMessageToCoalitionAction(coalition, text string, seconds int64) {
+    ac.actions = append(ac.actions, Action{
+        Type:    "message",
+        SubType: "coalition",
+        Message: text,
+        Params:  map[string]interface{}{"coalition": coalition, "duration": seconds},
+        Rule:    ac.rule,
+    })
+}
+
+// AddMessageToGroupAction shows a text message to a group for a number of seconds
+func (ac *ActionCollector) AddMessageToGroupAction(groupName, text string, seconds int64) {
+    ac.actions = append(ac.actions, Action{
+        Type:      "message",
+        SubType:   "group",
+        GroupName: groupName,
+        Message:   text,
+        Params:    map[string]interface{}{"duration": seconds},
+        Rule:      ac.rule,
+    })
+}
+
+// AddSoundAction plays a sound file from the mission to a coalition
+func (ac *ActionCollector) AddSoundAction(coalition, file string) {
+    ac.actions = append(ac.actions, Action{
+        Type:   "sound",
+        Params: map[string]interface{}{"coalition": coalition, "file": file},
+        Rule:   ac.rule,
+    })
+}
+
+// AddSmokeAction marks a zone with smoke: green, red, white, orange or blue
+func (ac *ActionCollector) AddSmokeAction(zone, color string) {
+    ac.actions = append(ac.actions, Action{
+        Type:   "smoke",
+        Zone:   zone,
+        Params: map[string]interface{}{"color": color},
+        Rule:   ac.rule,
+    })
+}
+
+// AddIlluminationAction drops an illumination flare over a zone at an altitude in metres
+func (ac *ActionCollector) AddIlluminationAction(zone string, altitude float64) {
+    ac.actions = append(ac.actions, Action{
+        Type:   "illumination",
+        Zone:   zone,
+        Params: map[string]interface{}{"altitude": altitude},
+        Rule:   ac.rule,
+    })
+}
+
+// AddAction adds an action of any type. params alternate keys and values,
+// e.g. Actions.AddAction("smoke", "marker", "zone", "BRAVO", "color", "red", "duration", 300).
+// Values may be strings, numbers or booleans. An action with a key without a
+// value or a key that is not a string is rejected.
+func (ac *ActionCollector) AddAction(actionType, subType string, params ...interface{}) {
+    action := Action{
+        Type:    actionType,
+        SubType: subType,
+        Rule:    ac.rule,
+        Params:  make(map[string]interface{}, len(params)/2),
+    }
+    for i := 0; i < len(params); i += 2 {
+        key, ok := params[i].(string)
+        if !ok {
+            action.problems = append(action.problems, fmt.Sprintf("parameter name %v is not a string", params[i]))
+            continue
+        }
+        if i+1 == len(params) {
+            action.problems = append(action.problems, fmt.Sprintf("parameter %s has no value", key))
+            continue
+        }
+        action.Params[key] = params[i+1]
     }
     ac.actions = append(ac.actions, action)
 }
 
+// RetryAction sends a failed action of the mission again, e.g. from a rule
+// on action_failed: Actions.RetryAction(Message.GetString("action_id"));
+// Retrying an action the mission does not know, one that did not fail or one
+// that was retried already is rejected.
+func (ac *ActionCollector) RetryAction(id string) {
+    var record ActionRecord
+    ok := false
+    if ac.log != nil {
+        record, ok = ac.log.Get(id)
+    }
+
+    var problem string
+    switch {
+    case !ok:
+        problem = fmt.Sprintf("RetryAction: unknown action %q", id)
+    case record.Status != ActionFailed || ac.retried[id]:
+        status := record.Status
+        if ac.retried[id] {
+            status = ActionRetried
+        }
+        problem = fmt.Sprintf("RetryAction: action %q is %s, only failed actions can be retried", id, status)
+    }
+    if problem != "" {
+        ac.actions = append(ac.actions, Action{
+            Type:     "retry",
+            RetryOf:  id,
+            Rule:     ac.rule,
+            problems: []string{problem},
+        })
+        return
+    }
+
+    if ac.retried == nil {
+        ac.retried = make(map[string]bool)
+    }
+    ac.retried[id] = true
+    ac.actions = append(ac.actions, record.Action.Retry(ac.rule))
+}
+
+// SetRule sets the rule credited with the actions added from now on
+func (ac *ActionCollector) SetRule(rule string) {
+    ac.rule = rule
+    ac.firingStart = len(ac.actions)
+}
+
+// DelayLast holds back the action the rule added last for a number of
+// seconds of mission time, e.g.
+// Actions.AddReinforceAction("SAM_BRAVO", "BRAVO", "SA-6", 2); Actions.DelayLast(600);
+// Calling it before the rule added an action is reported as a rejected delay action.
+func (ac *ActionCollector) DelayLast(seconds int64) {
+    if len(ac.actions) == ac.firingStart {
+        ac.actions = append(ac.actions, Action{
+            Type:     "delay",
+            Delay:    seconds,
+            Rule:     ac.rule,
+            problems: []string{"DelayLast: the rule has not added an action yet"},
+        })
+        return
+    }
+    ac.actions[len(ac.actions)-1].Delay = seconds
+}
+
 // GetActions returns all collected actions
 func (ac *ActionCollector) GetActions() []Action {
     return ac.actions
@@ -0,0 +1,134 @@
+// pkg/models/action_collector_test.go
+package models
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestActionCollectorRejectsMalformedCalls(t *testing.T) {
+    ac := NewActionCollectorWithLog(NewActionLog())
+    ac.SetRule("Broken")
+    ac.DelayLast(5)
+    ac.AddAction("smoke", "marker", "zone", "BRAVO", 3, "red", "color")
+    ac.RetryAction("act-unknown")
+
+    want := map[string][]string{
+        "delay": {"DelayLast: the rule has not added an action yet"},
+        "smoke": {"parameter name 3 is not a string", "parameter color has no value"},
+        "retry": {`RetryAction: unknown action "act-unknown"`},
+    }
+    actions := ac.GetActions()
+    if len(actions) != len(want) {
+        t.Fatalf("got %d actions, want %d", len(actions), len(want))
+    }
+    for _, action := range actions {
+        if !reflect.DeepEqual(action.Problems(), want[action.Type]) {
+            t.Errorf("%s action has problems %q, want %q", action.Type, action.Problems(), want[action.Type])
+        }
+        if action.Rule != "Broken" {
+            t.Errorf("%s action credited to %q", action.Type, action.Rule)
+        }
+    }
+}
+
+func TestActionCollectorRetriesFailedActionsOnce(t *testing.T) {
+    log := NewActionLog()
+    log.Record(Action{ID: "act-1", Type: "reinforce", GroupName: "SAM_BRAVO"}, ActionFailed, 10)
+    log.Record(Action{ID: "act-2", Type: "reinforce", GroupName: "SAM_ALPHA"}, ActionSent, 10)
+
+    ac := NewActionCollectorWithLog(log)
+    ac.SetRule("Retry")
+    ac.RetryAction("act-1")
+    ac.RetryAction("act-1")
+    ac.RetryAction("act-2")
+
+    actions := ac.GetActions()
+    if len(actions) != 3 {
+        t.Fatalf("got %d actions, want 3", len(actions))
+    }
+    if actions[0].RetryOf != "act-1" || actions[0].GroupName != "SAM_BRAVO" || len(actions[0].Problems()) != 0 {
+        t.Errorf("retry of act-1 is %+v", actions[0])
+    }
+    want := []string{
+        `RetryAction: action "act-1" is retried, only failed actions can be retried`,
+        `RetryAction: action "act-2" is sent, only failed actions can be retried`,
+    }
+    for i, problem := range want {
+        if got := actions[i+1].Problems(); !reflect.DeepEqual(got, []string{problem}) {
+            t.Errorf("action %d has problems %q, want %q", i+1, got, problem)
+        }
+    }
+}
+
+func TestActionDataPrecedence(t *testing.T) {
+    tests := []struct {
+        name   string
+        action Action
+        want   map[string]interface{}
+    }{
+        {
+            name:   "typed fields win over params",
+            action: Action{Type: "spawn", Zone: "BRAVO", UnitType: "SAM", Count: "2", Params: map[string]interface{}{"zone": "ALPHA", "count": 50, "heading": 90}},
+            want:   map[string]interface{}{"zone": "BRAVO", "unit_type": "SAM", "count": "2", "heading": 90},
+        },
+        {
+            name:   "params fill unset typed fields",
+            action: Action{Type: "smoke", Params: map[string]interface{}{"zone": "BRAVO", "color": "red"}},
+            want:   map[string]interface{}{"zone": "BRAVO", "color": "red"},
+        },
+    }
+
+    for _, tt := range tests {
+        if got := tt.action.Data(); !reflect.DeepEqual(got, tt.want) {
+            t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+        }
+    }
+}
+
+func TestActionCollectorCommands(t *testing.T) {
+    ac := NewActionCollector()
+    ac.SetRule("Commands")
+
+    tests := []struct {
+        name string
+        add  func()
+        want Action
+    }{
+        {"reinforce", func() { ac.AddReinforceAction("SAM_BRAVO", "BRAVO", "SA-6", 2) },
+            Action{Type: "reinforce", GroupName: "SAM_BRAVO", Zone: "BRAVO", UnitType: "SA-6", Count: "2"}},
+        {"despawn", func() { ac.AddDespawnAction("Convoy") },
+            Action{Type: "despawn", SubType: "remove", GroupName: "Convoy"}},
+        {"destroy group", func() { ac.AddDestroyGroupAction("Convoy") },
+            Action{Type: "despawn", SubType: "destroy", GroupName: "Convoy"}},
+        {"set ROE", func() { ac.AddSetROEAction("SAM_BRAVO", "weapon_hold") },
+            Action{Type: "set_roe", GroupName: "SAM_BRAVO", Params: map[string]interface{}{"roe": "weapon_hold"}}},
+        {"set alarm state", func() { ac.AddSetAlarmStateAction("SAM_BRAVO", "red") },
+            Action{Type: "set_alarm_state", GroupName: "SAM_BRAVO", Params: map[string]interface{}{"state": "red"}}},
+        {"set flag", func() { ac.AddSetFlagAction("42", 1) },
+            Action{Type: "set_flag", Params: map[string]interface{}{"flag": "42", "value": int64(1)}}},
+        {"message to coalition", func() { ac.AddMessageToCoalitionAction("blue", "SAM up", 10) },
+            Action{Type: "message", SubType: "coalition", Message: "SAM up", Params: map[string]interface{}{"coalition": "blue", "duration": int64(10)}}},
+        {"message to group", func() { ac.AddMessageToGroupAction("Viper", "RTB", 15) },
+            Action{Type: "message", SubType: "group", GroupName: "Viper", Message: "RTB", Params: map[string]interface{}{"duration": int64(15)}}},
+        {"sound", func() { ac.AddSoundAction("red", "alarm.ogg") },
+            Action{Type: "sound", Params: map[string]interface{}{"coalition": "red", "file": "alarm.ogg"}}},
+        {"smoke", func() { ac.AddSmokeAction("ALPHA", "green") },
+            Action{Type: "smoke", Zone: "ALPHA", Params: map[string]interface{}{"color": "green"}}},
+        {"illumination", func() { ac.AddIlluminationAction("ALPHA", 500) },
+            Action{Type: "illumination", Zone: "ALPHA", Params: map[string]interface{}{"altitude": 500.0}}},
+        {"generic action", func() { ac.AddAction("smoke", "marker", "zone", "BRAVO", "color", "red") },
+            Action{Type: "smoke", SubType: "marker", Params: map[string]interface{}{"zone": "BRAVO", "color": "red"}}},
+    }
+    for i, tt := range tests {
+        tt.add()
+        actions := ac.GetActions()
+        if len(actions) != i+1 {
+            t.Fatalf("%s: got %d actions, want %d", tt.name, len(actions), i+1)
+        }
+        tt.want.Rule = "Commands"
+        if got := actions[i]; !reflect.DeepEqual(got, tt.want) {
+            t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+        }
+    }
+}
@@ -0,0 +1,200 @@
+// pkg/models/action_log.go
+package models
+
+import "sync"
+
+// ActionStatus is how far an action got on its way to DCS
+type ActionStatus string
+
+// Action statuses
+const (
+    ActionScheduled ActionStatus = "scheduled" // Delayed, not sent yet
+    ActionCancelled ActionStatus = "cancelled" // Cancelled before it was sent
+    ActionSent      ActionStatus = "sent"      // Sent, not acknowledged yet
+    ActionExecuted  ActionStatus = "executed"  // Acknowledged by DCS
+    ActionFailed    ActionStatus = "failed"    // Reported as failed by DCS
+    ActionRetried   ActionStatus = "retried"   // Failed, then sent again under a new ID
+)
+
+// maxActionRecords bounds the action log of a mission; the oldest records are dropped first
+const maxActionRecords = 1000
+
+// ActionRecord is the status of an action the server handed out
+type ActionRecord struct {
+    ID        string       `json:"id"`
+    Action    Action       `json:"action"`
+    Status    ActionStatus `json:"status"`
+    Reason    string       `json:"reason,omitempty"`     // Why DCS could not execute the action
+    GroupName string       `json:"group_name,omitempty"` // Group DCS created or addressed
+    Attempt   int          `json:"attempt"`              // 1 for the original action, 2 for its first retry...
+    RetriedBy string       `json:"retried_by,omitempty"` // ID of the retry of a failed action
+    CreatedAt int64        `json:"created_at"`           // Mission time in seconds
+    UpdatedAt int64        `json:"updated_at"`
+}
+
+// ActionLog tracks the actions of a mission until DCS reports on them. Rules
+// can query it, e.g. ActionLog.CountFailed("reinforce") > 0.
+type ActionLog struct {
+    mu      sync.RWMutex
+    records map[string]*ActionRecord
+    order   []string // IDs, oldest first
+}
+
+// NewActionLog creates an empty action log
+func NewActionLog() *ActionLog {
+    return &ActionLog{
+        records: make(map[string]*ActionRecord),
+    }
+}
+
+// Record adds an action with its initial status. A retry continues the
+// attempt count of the action it retries.
+func (al *ActionLog) Record(action Action, status ActionStatus, at int64) {
+    al.mu.Lock()
+    defer al.mu.Unlock()
+
+    record := &ActionRecord{
+        ID:        action.ID,
+        Action:    action,
+        Status:    status,
+        Attempt:   1,
+        CreatedAt: at,
+        UpdatedAt: at,
+    }
+    if original, ok := al.records[action.RetryOf]; ok {
+        record.Attempt = original.Attempt + 1
+    }
+
+    if _, ok := al.records[action.ID]; !ok {
+        al.order = append(al.order, action.ID)
+    }
+    al.records[action.ID] = record
+
+    for len(al.order) > maxActionRecords {
+        delete(al.records, al.order[0])
+        al.order = al.order[1:]
+    }
+}
+
+// Update sets the status of an action. The reason and group name are kept
+// when empty.
+func (al *ActionLog) Update(id string, status ActionStatus, reason, groupName string, at int64) (ActionRecord, bool) {
+    al.mu.Lock()
+    defer al.mu.Unlock()
+
+    record, ok := al.records[id]
+    if !ok {
+        return ActionRecord{}, false
+    }
+    record.set(status, reason, groupName, at)
+    return *record, true
+}
+
+// Transition changes the status of an action only if it currently has the
+// status from, e.g. to accept an acknowledgement only for an action that was
+// sent. It returns the record and whether the status changed; the record of
+// an unknown action has no ID. The reason and group name are kept when empty.
+func (al *ActionLog) Transition(id string, from, to ActionStatus, reason, groupName string, at int64) (ActionRecord, bool) {
+    al.mu.Lock()
+    defer al.mu.Unlock()
+
+    record, ok := al.records[id]
+    if !ok {
+        return ActionRecord{}, false
+    }
+    if record.Status != from {
+        return *record, false
+    }
+    record.set(to, reason, groupName, at)
+    return *record, true
+}
+
+// MarkRetried records that a failed action was sent again as retryID. It
+// returns the record and whether it was failed, as for Transition.
+func (al *ActionLog) MarkRetried(id, retryID string, at int64) (ActionRecord, bool) {
+    al.mu.Lock()
+    defer al.mu.Unlock()
+
+    record, ok := al.records[id]
+    if !ok {
+        return ActionRecord{}, false
+    }
+    if record.Status != ActionFailed {
+        return *record, false
+    }
+    record.set(ActionRetried, "", "", at)
+    record.RetriedBy = retryID
+    return *record, true
+}
+
+// set changes the status of a record, keeping an empty reason and group name
+func (r *ActionRecord) set(status ActionStatus, reason, groupName string, at int64) {
+    r.Status = status
+    if reason != "" {
+        r.Reason = reason
+    }
+    if groupName != "" {
+        r.GroupName = groupName
+    }
+    r.UpdatedAt = at
+}
+
+// Get returns the record of an action
+func (al *ActionLog) Get(id string) (ActionRecord, bool) {
+    al.mu.RLock()
+    defer al.mu.RUnlock()
+
+    record, ok := al.records[id]
+    if !ok {
+        return ActionRecord{}, false
+    }
+    return *record, true
+}
+
+// List returns the records with a status, or all records for an empty status,
+// oldest first
+func (al *ActionLog) List(status ActionStatus) []ActionRecord {
+    al.mu.RLock()
+    defer al.mu.RUnlock()
+
+    records := make([]ActionRecord, 0)
+    for _, id := range al.order {
+        if record := al.records[id]; status == "" || record.Status == status {
+            records = append(records, *record)
+        }
+    }
+    return records
+}
+
+// CountStatus returns the number of actions with a status, e.g. "sent" for
+// actions DCS has not acknowledged yet
+func (al *ActionLog) CountStatus(status string) int {
+    return len(al.List(ActionStatus(status)))
+}
+
+// CountFailed returns the number of failed actions of a type that were not retried
+func (al *ActionLog) CountFailed(actionType string) int {
+    return al.CountFailedInZone(actionType, "")
+}
+
+// CountFailedInZone returns the number of failed actions of a type in a zone.
+// An empty zone matches any zone.
+func (al *ActionLog) CountFailedInZone(actionType, zone string) int {
+    count := 0
+    for _, record := range al.List(ActionFailed) {
+        if record.Action.Type == actionType && (zone == "" || record.Action.Zone == zone) {
+            count++
+        }
+    }
+    return count
+}
+
+// HasExecuted reports whether DCS executed an action of a type for a group
+func (al *ActionLog) HasExecuted(actionType, groupName string) bool {
+    for _, record := range al.List(ActionExecuted) {
+        if record.Action.Type == actionType && (record.GroupName == groupName || record.Action.GroupName == groupName) {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,52 @@
+// pkg/models/action_log_test.go
+package models
+
+import "testing"
+
+func TestActionLogTransitions(t *testing.T) {
+    log := NewActionLog()
+    log.Record(Action{ID: "act-1", Type: "reinforce", Zone: "BRAVO"}, ActionSent, 10)
+    log.Record(Action{ID: "act-2", Type: "reinforce", Zone: "BRAVO", Delay: 60}, ActionScheduled, 10)
+
+    steps := []struct {
+        name     string
+        id       string
+        from, to ActionStatus
+        ok       bool
+        status   ActionStatus // Status of the record afterwards
+    }{
+        {"ack of a sent action", "act-1", ActionSent, ActionFailed, true, ActionFailed},
+        {"second ack", "act-1", ActionSent, ActionExecuted, false, ActionFailed},
+        {"ack of a scheduled action", "act-2", ActionSent, ActionExecuted, false, ActionScheduled},
+        {"unknown action", "act-9", ActionSent, ActionExecuted, false, ""},
+    }
+    for _, step := range steps {
+        record, ok := log.Transition(step.id, step.from, step.to, "zone not found", "", 20)
+        if ok != step.ok || record.Status != step.status {
+            t.Errorf("%s: got %v with status %q, want %v with %q", step.name, ok, record.Status, step.ok, step.status)
+        }
+    }
+    if record, _ := log.Get("act-1"); record.Reason != "zone not found" || record.UpdatedAt != 20 {
+        t.Errorf("failed record %+v lacks the reason or time of the ack", record)
+    }
+    if log.CountFailed("reinforce") != 1 {
+        t.Errorf("CountFailed = %d before the retry, want 1", log.CountFailed("reinforce"))
+    }
+
+    if _, ok := log.MarkRetried("act-1", "act-3", 30); !ok {
+        t.Fatal("failed action could not be marked retried")
+    }
+    if _, ok := log.MarkRetried("act-1", "act-4", 31); ok {
+        t.Error("action was marked retried twice")
+    }
+    if _, ok := log.MarkRetried("act-2", "act-4", 31); ok {
+        t.Error("scheduled action was marked retried")
+    }
+    record, _ := log.Get("act-1")
+    if record.Status != ActionRetried || record.RetriedBy != "act-3" {
+        t.Errorf("retried record is %s by %q, want retried by act-3", record.Status, record.RetriedBy)
+    }
+    if log.CountFailed("reinforce") != 0 {
+        t.Errorf("CountFailed = %d after the retry, want 0", log.CountFailed("reinforce"))
+    }
+}
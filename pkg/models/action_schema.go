@@ -0,0 +1,200 @@
+// pkg/models/action_schema.go
+package models
+
+import (
+    "fmt"
+    "math"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// ParamType is the type of an action parameter as the Lua executor expects it
+type ParamType string
+
+const (
+    ParamString  ParamType = "string"
+    ParamInteger ParamType = "integer" // A whole number, or a string holding one
+    ParamNumber  ParamType = "number"  // Any number, or a string holding one
+    ParamBoolean ParamType = "boolean"
+)
+
+// ActionParam declares one key of an action's data
+type ActionParam struct {
+    Name     string    `json:"name"`
+    Type     ParamType `json:"type"`
+    Required bool      `json:"required,omitempty"`
+    Enum     []string  `json:"enum,omitempty"` // Allowed values of a string parameter
+}
+
+// ActionSchema declares the parameters of an action type. Keys that are not
+// declared are passed through unchecked.
+type ActionSchema struct {
+    Type     string        `json:"type"`
+    SubTypes []string      `json:"sub_types,omitempty"` // Allowed sub types; empty allows any
+    Params   []ActionParam `json:"params"`
+}
+
+// DefaultActionSchemas returns the schemas of the actions ActionCollector can produce
+func DefaultActionSchemas() []ActionSchema {
+    group := ActionParam{Name: "group_name", Type: ParamString, Required: true}
+    zone := ActionParam{Name: "zone", Type: ParamString, Required: true}
+    coalition := []string{"red", "blue", "neutral"}
+
+    return []ActionSchema{
+        {Type: "spawn", Params: []ActionParam{
+            zone,
+            {Name: "unit_type", Type: ParamString, Required: true},
+            {Name: "count", Type: ParamInteger, Required: true},
+        }},
+        {Type: "alert", Params: []ActionParam{
+            {Name: "level", Type: ParamString, Required: true},
+            {Name: "message", Type: ParamString, Required: true},
+            {Name: "zone", Type: ParamString},
+        }},
+        {Type: "reinforce", Params: []ActionParam{
+            group,
+            zone,
+            {Name: "unit_type", Type: ParamString, Required: true},
+            {Name: "count", Type: ParamInteger, Required: true},
+        }},
+        {Type: "despawn", SubTypes: []string{"remove", "destroy"}, Params: []ActionParam{group}},
+        {Type: "set_roe", Params: []ActionParam{
+            group,
+            {Name: "roe", Type: ParamString, Required: true, Enum: []string{"weapons_free", "open_fire", "return_fire", "weapon_hold"}},
+        }},
+        {Type: "set_alarm_state", Params: []ActionParam{
+            group,
+            {Name: "state", Type: ParamString, Required: true, Enum: []string{"auto", "green", "red"}},
+        }},
+        {Type: "set_flag", Params: []ActionParam{
+            {Name: "flag", Type: ParamString, Required: true},
+            {Name: "value", Type: ParamInteger, Required: true},
+        }},
+        {Type: "message", SubTypes: []string{"coalition", "group"}, Params: []ActionParam{
+            {Name: "message", Type: ParamString, Required: true},
+            {Name: "coalition", Type: ParamString, Enum: coalition},
+            {Name: "group_name", Type: ParamString},
+            {Name: "duration", Type: ParamInteger},
+        }},
+        {Type: "sound", Params: []ActionParam{
+            {Name: "coalition", Type: ParamString, Required: true, Enum: coalition},
+            {Name: "file", Type: ParamString, Required: true},
+        }},
+        {Type: "smoke", Params: []ActionParam{
+            zone,
+            {Name: "color", Type: ParamString, Required: true, Enum: []string{"green", "red", "white", "orange", "blue"}},
+        }},
+        {Type: "illumination", Params: []ActionParam{
+            zone,
+            {Name: "altitude", Type: ParamNumber, Required: true},
+        }},
+    }
+}
+
+// ActionSchemaRegistry holds the schema of every known action type
+type ActionSchemaRegistry struct {
+    schemas map[string]ActionSchema
+}
+
+// NewActionSchemaRegistry creates a registry from schemas. A schema replaces
+// an earlier one of the same type, so configured schemas can override the defaults.
+func NewActionSchemaRegistry(schemas []ActionSchema) *ActionSchemaRegistry {
+    registry := &ActionSchemaRegistry{schemas: make(map[string]ActionSchema)}
+    for _, schema := range schemas {
+        registry.schemas[schema.Type] = schema
+    }
+    return registry
+}
+
+// Schemas returns every schema, sorted by action type
+func (r *ActionSchemaRegistry) Schemas() []ActionSchema {
+    schemas := make([]ActionSchema, 0, len(r.schemas))
+    for _, schema := range r.schemas {
+        schemas = append(schemas, schema)
+    }
+    sort.Slice(schemas, func(i, j int) bool { return schemas[i].Type < schemas[j].Type })
+    return schemas
+}
+
+// Validate checks an action against the schema of its type and returns every
+// problem found. Actions of a type without a schema pass unchecked.
+func (r *ActionSchemaRegistry) Validate(action Action) []string {
+    schema, ok := r.schemas[action.Type]
+    if !ok {
+        return nil
+    }
+
+    var problems []string
+    if len(schema.SubTypes) > 0 && !containsValue(schema.SubTypes, action.SubType) {
+        problems = append(problems, fmt.Sprintf("sub type %q is not one of %s", action.SubType, strings.Join(schema.SubTypes, ", ")))
+    }
+
+    data := action.Data()
+    for _, param := range schema.Params {
+        value, present := data[param.Name]
+        if !present || value == nil || value == "" {
+            if param.Required {
+                problems = append(problems, fmt.Sprintf("%s is required", param.Name))
+            }
+            continue
+        }
+        if problem := param.check(value); problem != "" {
+            problems = append(problems, fmt.Sprintf("%s %s", param.Name, problem))
+        }
+    }
+    return problems
+}
+
+// check returns what is wrong with a parameter value, or ""
+func (p ActionParam) check(value interface{}) string {
+    switch p.Type {
+    case ParamString:
+        s, ok := value.(string)
+        if !ok {
+            return fmt.Sprintf("must be a string, got %v", value)
+        }
+        if len(p.Enum) > 0 && !containsValue(p.Enum, s) {
+            return fmt.Sprintf("%q is not one of %s", s, strings.Join(p.Enum, ", "))
+        }
+    case ParamInteger:
+        f, ok := numericValue(value)
+        if !ok || f != math.Trunc(f) {
+            return fmt.Sprintf("must be an integer, got %v", value)
+        }
+    case ParamNumber:
+        if _, ok := numericValue(value); !ok {
+            return fmt.Sprintf("must be a number, got %v", value)
+        }
+    case ParamBoolean:
+        if _, ok := value.(bool); !ok {
+            return fmt.Sprintf("must be a boolean, got %v", value)
+        }
+    }
+    return ""
+}
+
+// numericValue converts a number or numeric string
+func numericValue(value interface{}) (float64, bool) {
+    switch v := value.(type) {
+    case int:
+        return float64(v), true
+    case int64:
+        return float64(v), true
+    case float64:
+        return v, true
+    case string:
+        f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+        return f, err == nil
+    }
+    return 0, false
+}
+
+func containsValue(values []string, value string) bool {
+    for _, v := range values {
+        if v == value {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,85 @@
+// pkg/models/action_schema_test.go
+package models
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestActionSchemaValidate(t *testing.T) {
+    registry := NewActionSchemaRegistry(append(DefaultActionSchemas(), ActionSchema{
+        Type: "jammer",
+        Params: []ActionParam{
+            {Name: "enabled", Type: ParamBoolean, Required: true},
+            {Name: "power", Type: ParamNumber},
+        },
+    }))
+
+    tests := []struct {
+        name   string
+        action Action
+        want   []string
+    }{
+        {"valid spawn", Action{Type: "spawn", Zone: "ALPHA", UnitType: "T-72", Count: "2"}, nil},
+        {"missing required parameters", Action{Type: "spawn", Zone: "ALPHA"},
+            []string{"unit_type is required", "count is required"}},
+        {"integer as string", Action{Type: "set_flag", Params: map[string]interface{}{"flag": "7", "value": "3"}}, nil},
+        {"fractional integer", Action{Type: "set_flag", Params: map[string]interface{}{"flag": "7", "value": 1.5}},
+            []string{"value must be an integer, got 1.5"}},
+        {"string of another type", Action{Type: "set_flag", Params: map[string]interface{}{"flag": 7, "value": 1}},
+            []string{"flag must be a string, got 7"}},
+        {"value outside the enum", Action{Type: "smoke", Zone: "ALPHA", Params: map[string]interface{}{"color": "purple"}},
+            []string{`color "purple" is not one of green, red, white, orange, blue`}},
+        {"unknown sub type", Action{Type: "despawn", SubType: "vanish", GroupName: "Convoy"},
+            []string{`sub type "vanish" is not one of remove, destroy`}},
+        {"non-numeric number", Action{Type: "illumination", Zone: "ALPHA", Params: map[string]interface{}{"altitude": "high"}},
+            []string{"altitude must be a number, got high"}},
+        {"undeclared parameters pass", Action{Type: "smoke", Zone: "ALPHA", Params: map[string]interface{}{"color": "red", "duration": 300}}, nil},
+        {"configured type", Action{Type: "jammer", Params: map[string]interface{}{"enabled": true, "power": "0.5"}}, nil},
+        {"configured boolean", Action{Type: "jammer", Params: map[string]interface{}{"enabled": "yes"}},
+            []string{"enabled must be a boolean, got yes"}},
+        {"type without a schema passes", Action{Type: "flare", Params: map[string]interface{}{"anything": []int{1}}}, nil},
+    }
+    for _, tt := range tests {
+        if got := registry.Validate(tt.action); !reflect.DeepEqual(got, tt.want) {
+            t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+        }
+    }
+}
+
+func TestActionSchemaOverride(t *testing.T) {
+    registry := NewActionSchemaRegistry(append(DefaultActionSchemas(), ActionSchema{
+        Type:   "smoke",
+        Params: []ActionParam{{Name: "zone", Type: ParamString, Required: true}},
+    }))
+    if problems := registry.Validate(Action{Type: "smoke", Zone: "ALPHA", Params: map[string]interface{}{"color": "purple"}}); problems != nil {
+        t.Errorf("the configured smoke schema did not replace the default: %q", problems)
+    }
+    if n := len(registry.Schemas()); n != len(DefaultActionSchemas()) {
+        t.Errorf("got %d schemas, want %d", n, len(DefaultActionSchemas()))
+    }
+}
+
+func TestDefaultSchemasAcceptCollectorActions(t *testing.T) {
+    ac := NewActionCollector()
+    ac.AddSpawnAction("reinforcement", "BRAVO", "T-72", "2")
+    ac.AddAlertAction("alert", "red", "Enemy in ALPHA")
+    ac.AddReinforceAction("SAM_BRAVO", "BRAVO", "SA-6", 2)
+    ac.AddDespawnAction("Convoy")
+    ac.AddDestroyGroupAction("Convoy")
+    ac.AddSetROEAction("SAM_BRAVO", "weapon_hold")
+    ac.AddSetAlarmStateAction("SAM_BRAVO", "red")
+    ac.AddSetFlagAction("42", 1)
+    ac.AddMessageToCoalitionAction("blue", "SAM up", 10)
+    ac.AddMessageToGroupAction("Viper", "RTB", 15)
+    ac.AddSoundAction("red", "alarm.ogg")
+    ac.AddSmokeAction("ALPHA", "green")
+    ac.AddIlluminationAction("ALPHA", 500)
+
+    registry := NewActionSchemaRegistry(DefaultActionSchemas())
+    for _, action := range ac.GetActions() {
+        if problems := registry.Validate(action); problems != nil {
+            t.Errorf("%s %s action: %q", action.Type, action.SubType, problems)
+        }
+    }
+}
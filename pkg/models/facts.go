@@ -1,8 +1,16 @@
 package models
 
+import (
+    "fmt"
+    "sort"
+    "strconv"
+    "sync"
+)
+
 // Fact represents a piece of information for the rules engine
 type Fact struct {
     ID        string `json:"id,omitempty"`
+    MissionID string `json:"mission_id,omitempty"`
     Type      string `json:"type"`
     Value     string `json:"value"`
     Zone      string `json:"zone,omitempty"`
@@ -10,3 +18,149 @@ type Fact struct {
     GroupName string `json:"group_name,omitempty"`
     Count     string `json:"count,omitempty"`
 }
+
+// FactStore is the working memory of asserted facts for one mission. Facts
+// stay until they are retracted or the mission restarts, and are exposed to
+// rules as the Facts fact.
+type FactStore struct {
+    mu     sync.RWMutex
+    facts  map[string]*Fact
+    nextID int
+}
+
+// NewFactStore creates an empty fact store
+func NewFactStore() *FactStore {
+    return &FactStore{facts: make(map[string]*Fact)}
+}
+
+// Assert adds a fact, or replaces the fact with the same ID. A fact without an
+// ID gets a generated one. It returns the stored fact and whether it is new.
+func (fs *FactStore) Assert(fact Fact) (Fact, bool) {
+    fs.mu.Lock()
+    defer fs.mu.Unlock()
+
+    if fact.ID == "" {
+        for {
+            fs.nextID++
+            fact.ID = fmt.Sprintf("fact-%d", fs.nextID)
+            if _, taken := fs.facts[fact.ID]; !taken {
+                break
+            }
+        }
+    }
+    _, exists := fs.facts[fact.ID]
+    stored := fact
+    fs.facts[fact.ID] = &stored
+    return stored, !exists
+}
+
+// Retract removes a fact and reports whether it existed
+func (fs *FactStore) Retract(id string) (Fact, bool) {
+    fs.mu.Lock()
+    defer fs.mu.Unlock()
+
+    fact, ok := fs.facts[id]
+    if !ok {
+        return Fact{}, false
+    }
+    delete(fs.facts, id)
+    return *fact, true
+}
+
+// List returns a copy of every fact, sorted by ID
+func (fs *FactStore) List() []Fact {
+    fs.mu.RLock()
+    defer fs.mu.RUnlock()
+
+    facts := make([]Fact, 0, len(fs.facts))
+    for _, fact := range fs.facts {
+        facts = append(facts, *fact)
+    }
+    sort.Slice(facts, func(i, j int) bool { return facts[i].ID < facts[j].ID })
+    return facts
+}
+
+// find returns the facts matching all non-empty filters
+func (fs *FactStore) find(factType, zone string) []*Fact {
+    fs.mu.RLock()
+    defer fs.mu.RUnlock()
+
+    var result []*Fact
+    for _, fact := range fs.facts {
+        if (factType == "" || fact.Type == factType) && (zone == "" || fact.Zone == zone) {
+            result = append(result, fact)
+        }
+    }
+    return result
+}
+
+// Has reports whether a fact with the ID exists
+func (fs *FactStore) Has(id string) bool {
+    fs.mu.RLock()
+    defer fs.mu.RUnlock()
+    _, ok := fs.facts[id]
+    return ok
+}
+
+// HasType reports whether any fact of the type exists
+func (fs *FactStore) HasType(factType string) bool {
+    return len(fs.find(factType, "")) > 0
+}
+
+// HasTypeInZone reports whether any fact of the type exists for the zone
+func (fs *FactStore) HasTypeInZone(factType, zone string) bool {
+    return len(fs.find(factType, zone)) > 0
+}
+
+// HasValue reports whether a fact of the type has the value
+func (fs *FactStore) HasValue(factType, value string) bool {
+    for _, fact := range fs.find(factType, "") {
+        if fact.Value == value {
+            return true
+        }
+    }
+    return false
+}
+
+// CountType returns the number of facts of the type
+func (fs *FactStore) CountType(factType string) int {
+    return len(fs.find(factType, ""))
+}
+
+// CountTypeInZone returns the number of facts of the type for the zone
+func (fs *FactStore) CountTypeInZone(factType, zone string) int {
+    return len(fs.find(factType, zone))
+}
+
+// SumCount returns the total Count of the facts of the type. A Count that is
+// not a number counts as 0.
+func (fs *FactStore) SumCount(factType string) int {
+    total := 0
+    for _, fact := range fs.find(factType, "") {
+        if count, err := strconv.Atoi(fact.Count); err == nil {
+            total += count
+        }
+    }
+    return total
+}
+
+// GetValue returns the value of the fact with the ID, or ""
+func (fs *FactStore) GetValue(id string) string {
+    fs.mu.RLock()
+    defer fs.mu.RUnlock()
+    if fact, ok := fs.facts[id]; ok {
+        return fact.Value
+    }
+    return ""
+}
+
+// GetValueInZone returns the value of a fact of the type for the zone, or "".
+// If there are several, the one with the lowest ID is used.
+func (fs *FactStore) GetValueInZone(factType, zone string) string {
+    facts := fs.find(factType, zone)
+    if len(facts) == 0 {
+        return ""
+    }
+    sort.Slice(facts, func(i, j int) bool { return facts[i].ID < facts[j].ID })
+    return facts[0].Value
+}
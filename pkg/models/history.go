@@ -0,0 +1,125 @@
+// pkg/models/history.go
+package models
+
+import (
+    "sort"
+    "sync"
+)
+
+// historyEntry is a message together with the mission time it is filed under
+type historyEntry struct {
+    at      int64
+    message *Message
+}
+
+// MessageHistory keeps the messages of one mission from the last Window
+// seconds of mission time. Mission time is taken from the message
+// timestamps; the latest timestamp seen is "now". Messages without a
+// timestamp are filed at the current mission time, or at the time given to
+// AddAt. It is exposed to rules as the History fact.
+type MessageHistory struct {
+    Window int64 // Seconds of mission time kept
+
+    mu      sync.RWMutex
+    entries []historyEntry // Sorted by time
+    now     int64
+}
+
+// maxHistoryEntries bounds the messages kept by a history; the oldest are
+// dropped first, even inside the window
+const maxHistoryEntries = 10000
+
+// NewMessageHistory creates an empty history keeping window seconds of messages
+func NewMessageHistory(window int64) *MessageHistory {
+    return &MessageHistory{Window: window}
+}
+
+// Add records a message at its timestamp and drops the messages that fell out
+// of the window
+func (h *MessageHistory) Add(m *Message) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+
+    at := m.Timestamp
+    if at == 0 {
+        at = h.now
+    }
+    h.add(m, at)
+}
+
+// AddAt records a message at a mission time, e.g. the mission clock's time for
+// a message without a timestamp, and drops the messages that fell out of the window
+func (h *MessageHistory) AddAt(m *Message, at int64) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    h.add(m, at)
+}
+
+// add files a message and prunes the history. Callers hold the lock.
+func (h *MessageHistory) add(m *Message, at int64) {
+    if at > h.now {
+        h.now = at
+    }
+
+    // Events may arrive slightly out of order; keep the entries sorted
+    i := sort.Search(len(h.entries), func(i int) bool { return h.entries[i].at > at })
+    h.entries = append(h.entries, historyEntry{})
+    copy(h.entries[i+1:], h.entries[i:])
+    h.entries[i] = historyEntry{at: at, message: m}
+
+    cutoff := h.now - h.Window
+    drop := sort.Search(len(h.entries), func(i int) bool { return h.entries[i].at >= cutoff })
+    if len(h.entries)-drop > maxHistoryEntries {
+        drop = len(h.entries) - maxHistoryEntries
+    }
+    if drop > 0 {
+        h.entries = append(h.entries[:0], h.entries[drop:]...)
+    }
+}
+
+// Now returns the current mission time, the latest timestamp seen
+func (h *MessageHistory) Now() int64 {
+    h.mu.RLock()
+    defer h.mu.RUnlock()
+    return h.now
+}
+
+// Len returns the number of messages in the window
+func (h *MessageHistory) Len() int {
+    h.mu.RLock()
+    defer h.mu.RUnlock()
+    return len(h.entries)
+}
+
+// Within returns the messages from the last seconds of mission time as a
+// collection, so every MessageCollection query can be applied to them:
+// History.Within(180).CountMessagesByEventAndZone("unit_detected", "ALPHA")
+func (h *MessageHistory) Within(seconds int64) *MessageCollection {
+    h.mu.RLock()
+    defer h.mu.RUnlock()
+
+    collection := NewMessageCollection()
+    cutoff := h.now - seconds
+    for _, entry := range h.entries {
+        if entry.at >= cutoff {
+            collection.AddMessage(entry.message)
+        }
+    }
+    return collection
+}
+
+// All returns every message in the window as a collection
+func (h *MessageHistory) All() *MessageCollection {
+    return h.Within(h.Window)
+}
+
+// CountEventsWithin returns the number of events of a type in the last seconds of mission time
+func (h *MessageHistory) CountEventsWithin(eventType string, seconds int64) int {
+    return h.Within(seconds).CountMessagesByEvent(eventType)
+}
+
+// CountEventsInZoneWithin returns the number of events of a type in the zone
+// in the last seconds of mission time
+func (h *MessageHistory) CountEventsInZoneWithin(eventType, zone string, seconds int64) int {
+    return h.Within(seconds).CountMessagesByEventAndZone(eventType, zone)
+}
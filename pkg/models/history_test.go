@@ -0,0 +1,55 @@
+// pkg/models/history_test.go
+package models
+
+import "testing"
+
+func TestMessageHistoryPrunesUntimestampedMessages(t *testing.T) {
+    h := NewMessageHistory(60)
+
+    // Untimestamped messages filed at the mission clock's time leave the window
+    for clock := int64(0); clock < 1000; clock++ {
+        h.AddAt(NewMessage("unit_detected"), clock)
+    }
+    if h.Len() != 61 {
+        t.Errorf("history kept %d messages, want the 61 of the last 60s", h.Len())
+    }
+    if h.Now() != 999 {
+        t.Errorf("Now() = %d, want 999", h.Now())
+    }
+}
+
+func TestMessageHistoryCap(t *testing.T) {
+    h := NewMessageHistory(60)
+    for i := 0; i < maxHistoryEntries+10; i++ {
+        h.Add(NewMessage("unit_detected"))
+    }
+    if h.Len() != maxHistoryEntries {
+        t.Errorf("history kept %d messages, want %d", h.Len(), maxHistoryEntries)
+    }
+}
+
+func TestMessageHistoryWindow(t *testing.T) {
+    h := NewMessageHistory(300)
+    for _, at := range []int64{100, 250, 200, 400, 500} {
+        m := NewMessage("unit_detected")
+        m.Timestamp = at
+        h.Add(m)
+    }
+    late := NewMessage("unit_lost")
+    h.Add(late)
+
+    tests := []struct {
+        seconds int64
+        want    int
+    }{
+        {0, 2}, // The message at 500 and the untimestamped one filed at 500
+        {100, 3},
+        {300, 5}, // The message at 100 fell out of the window at 500
+        {1000, 5},
+    }
+    for _, tt := range tests {
+        if got := h.Within(tt.seconds).Count(); got != tt.want {
+            t.Errorf("Within(%d) has %d messages, want %d", tt.seconds, got, tt.want)
+        }
+    }
+}
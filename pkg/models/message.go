@@ -1,15 +1,92 @@
 // pkg/models/message.go
 package models
 
+import (
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// AlertLevel is the alert level reported by an alert_level_change event
+type AlertLevel string
+
+const (
+    AlertLevelGreen  AlertLevel = "green"
+    AlertLevelYellow AlertLevel = "yellow"
+    AlertLevelRed    AlertLevel = "red"
+)
+
+// ParseAlertLevel validates an alert level, ignoring case
+func ParseAlertLevel(level string) (AlertLevel, error) {
+    switch AlertLevel(strings.ToLower(strings.TrimSpace(level))) {
+    case AlertLevelGreen:
+        return AlertLevelGreen, nil
+    case AlertLevelYellow:
+        return AlertLevelYellow, nil
+    case AlertLevelRed:
+        return AlertLevelRed, nil
+    }
+    return "", fmt.Errorf("unknown alert level %q, expected %s, %s or %s", level, AlertLevelGreen, AlertLevelYellow, AlertLevelRed)
+}
+
+// Coalition is the side a unit belongs to
+type Coalition string
+
+const (
+    CoalitionRed     Coalition = "red"
+    CoalitionBlue    Coalition = "blue"
+    CoalitionNeutral Coalition = "neutral"
+)
+
+// ParseCoalition validates a coalition name, ignoring case. DCS coalition
+// numbers (0 neutral, 1 red, 2 blue) are accepted as well.
+func ParseCoalition(coalition string) (Coalition, error) {
+    switch strings.ToLower(strings.TrimSpace(coalition)) {
+    case "red", "1":
+        return CoalitionRed, nil
+    case "blue", "2":
+        return CoalitionBlue, nil
+    case "neutral", "neutrals", "0":
+        return CoalitionNeutral, nil
+    }
+    return "", fmt.Errorf("unknown coalition %q, expected %s, %s or %s", coalition, CoalitionRed, CoalitionBlue, CoalitionNeutral)
+}
+
+// Is reports whether c is the coalition named by a rule. Both are parsed like
+// event data, so "Blue" and "2" both name the blue coalition
+func (c Coalition) Is(coalition string) bool {
+    parsed, err := ParseCoalition(coalition)
+    if err != nil {
+        return false
+    }
+    own, err := ParseCoalition(string(c))
+    return err == nil && own == parsed
+}
+
 // Message represents a direct message event from DCS
 type Message struct {
-    Event     string `json:"event"`
-    Zone      string `json:"zone"`
-    UnitType  string `json:"unit_type"`
-    UnitName  string `json:"unit_name"`
-    GroupName string `json:"group_name"`
-    Level     string `json:"level"`
-    Count     string `json:"count"`
+    MissionID string     `json:"mission_id"`
+    Event     string     `json:"event"`
+    Timestamp int64      `json:"timestamp"`
+    Zone      string     `json:"zone"`
+    UnitType  string     `json:"unit_type"`
+    UnitName  string     `json:"unit_name"`
+    GroupName string     `json:"group_name"`
+    Coalition Coalition  `json:"coalition"`
+    Level     AlertLevel `json:"level"`
+    Count     int        `json:"count"`
+
+    // Position is only meaningful when HasPosition is set
+    Position    Position `json:"position"`
+    HasPosition bool     `json:"has_position"`
+
+    // Zones lists every configured zone containing Position
+    Zones []string `json:"zones,omitempty"`
+
+    // Data is the complete event payload as sent by DCS. Rules read fields
+    // that have no dedicated Message field through GetString, GetNumber and
+    // GetBool, e.g. Message.GetString("coalition") or Message.GetNumber("position.x").
+    Data map[string]interface{} `json:"data,omitempty"`
 }
 
 func NewMessage(event string) *Message {
@@ -17,3 +94,87 @@ func NewMessage(event string) *Message {
         Event: event,
     }
 }
+
+// HasField reports whether the event payload contains the dotted path
+func (m *Message) HasField(path string) bool {
+    _, ok := m.lookup(path)
+    return ok
+}
+
+// GetString returns the string at the dotted path of the event payload.
+// Numbers and booleans are formatted; a missing field yields "".
+func (m *Message) GetString(path string) string {
+    val, ok := m.lookup(path)
+    if !ok || val == nil {
+        return ""
+    }
+    switch v := val.(type) {
+    case string:
+        return v
+    case float64:
+        return strconv.FormatFloat(v, 'f', -1, 64)
+    case bool:
+        return strconv.FormatBool(v)
+    }
+    return fmt.Sprintf("%v", val)
+}
+
+// GetNumber returns the number at the dotted path of the event payload.
+// Numeric strings are parsed; a missing or non-numeric field yields 0.
+func (m *Message) GetNumber(path string) float64 {
+    val, ok := m.lookup(path)
+    if !ok {
+        return 0
+    }
+    switch v := val.(type) {
+    case float64:
+        return v
+    case string:
+        if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+            return f
+        }
+    }
+    return 0
+}
+
+// GetBool returns the boolean at the dotted path of the event payload.
+// The strings "true" and "false" are parsed; anything else yields false.
+func (m *Message) GetBool(path string) bool {
+    val, ok := m.lookup(path)
+    if !ok {
+        return false
+    }
+    switch v := val.(type) {
+    case bool:
+        return v
+    case string:
+        b, _ := strconv.ParseBool(strings.TrimSpace(v))
+        return b
+    }
+    return false
+}
+
+// lookup walks a dotted path such as "position.x" or "targets.0.name" through
+// nested objects and arrays of the event payload
+func (m *Message) lookup(path string) (interface{}, bool) {
+    var current interface{} = m.Data
+    for _, key := range strings.Split(path, ".") {
+        switch node := current.(type) {
+        case map[string]interface{}:
+            val, ok := node[key]
+            if !ok {
+                return nil, false
+            }
+            current = val
+        case []interface{}:
+            index, err := strconv.Atoi(key)
+            if err != nil || index < 0 || index >= len(node) {
+                return nil, false
+            }
+            current = node[index]
+        default:
+            return nil, false
+        }
+    }
+    return current, true
+}
@@ -1,10 +1,6 @@
 // pkg/models/message_collection.go
 package models
 
-import (
-	"strconv"
-)
-
 // MessageCollection holds multiple messages for batch processing
 type MessageCollection struct {
     Messages []*Message
@@ -37,7 +33,7 @@ func (mc *MessageCollection) GetMessagesByEvent(eventType string) []*Message {
 func (mc *MessageCollection) GetMessagesFromZone(zone string) []*Message {
     var result []*Message
     for _, msg := range mc.Messages {
-        if msg.Zone == zone {
+        if msg.InZone(zone) {
             result = append(result, msg)
         }
     }
@@ -48,13 +44,18 @@ func (mc *MessageCollection) GetMessagesFromZone(zone string) []*Message {
 func (mc *MessageCollection) GetMessagesByEventAndZone(eventType, zone string) []*Message {
     var result []*Message
     for _, msg := range mc.Messages {
-        if msg.Event == eventType && msg.Zone == zone {
+        if msg.Event == eventType && msg.InZone(zone) {
             result = append(result, msg)
         }
     }
     return result
 }
 
+// CountMessagesByEventAndZone returns the count of messages of a specific event type from a specific zone
+func (mc *MessageCollection) CountMessagesByEventAndZone(eventType, zone string) int {
+    return len(mc.GetMessagesByEventAndZone(eventType, zone))
+}
+
 // CountMessagesByEvent returns the count of messages of a specific event type
 func (mc *MessageCollection) CountMessagesByEvent(eventType string) int {
     count := 0
@@ -66,16 +67,18 @@ func (mc *MessageCollection) CountMessagesByEvent(eventType string) int {
     return count
 }
 
-// HasDetectionsInBothZones checks if there are unit_detected events in both specified zones
+// HasDetectionsInBothZones checks if there are unit_detected events in both specified zones.
+// A detection counts for one zone only, zone1 first, so one in an overlap of
+// the two zones counts for zone1 and passing the same zone twice never matches.
 func (mc *MessageCollection) HasDetectionsInBothZones(zone1, zone2 string) bool {
     hasZone1 := false
     hasZone2 := false
     
     for _, msg := range mc.Messages {
         if msg.Event == "unit_detected" {
-            if msg.Zone == zone1 {
+            if msg.InZone(zone1) {
                 hasZone1 = true
-            } else if msg.Zone == zone2 {
+            } else if msg.InZone(zone2) {
                 hasZone2 = true
             }
             
@@ -90,17 +93,75 @@ func (mc *MessageCollection) HasDetectionsInBothZones(zone1, zone2 string) bool
 
 // GetTotalDetectedUnits returns the total count of detected units across all messages
 func (mc *MessageCollection) GetTotalDetectedUnits() int {
+    return mc.WithEvent("unit_detected").SumCount()
+}
+
+// GetMessagesByCoalition returns all messages about units of a coalition
+func (mc *MessageCollection) GetMessagesByCoalition(coalition string) []*Message {
+    var result []*Message
+    for _, msg := range mc.Messages {
+        if msg.Coalition.Is(coalition) {
+            result = append(result, msg)
+        }
+    }
+    return result
+}
+
+// CountMessagesByCoalition returns the count of messages about units of a coalition
+func (mc *MessageCollection) CountMessagesByCoalition(coalition string) int {
+    return len(mc.GetMessagesByCoalition(coalition))
+}
+
+// CountMessagesByEventAndCoalition returns the count of messages of a specific
+// event type about units of a coalition, e.g. ("unit_destroyed", "blue") for friendly losses
+func (mc *MessageCollection) CountMessagesByEventAndCoalition(eventType, coalition string) int {
+    count := 0
+    for _, msg := range mc.Messages {
+        if msg.Event == eventType && msg.Coalition.Is(coalition) {
+            count++
+        }
+    }
+    return count
+}
+
+// HasCoalitionInZone checks if there is any message about a unit of the coalition in the zone
+func (mc *MessageCollection) HasCoalitionInZone(coalition, zone string) bool {
+    for _, msg := range mc.Messages {
+        if msg.Coalition.Is(coalition) && msg.InZone(zone) {
+            return true
+        }
+    }
+    return false
+}
+
+// HasDetectionsOfCoalitionInZone checks if units of the coalition were detected in the zone
+func (mc *MessageCollection) HasDetectionsOfCoalitionInZone(coalition, zone string) bool {
+    for _, msg := range mc.Messages {
+        if msg.Event == "unit_detected" && msg.Coalition.Is(coalition) && msg.InZone(zone) {
+            return true
+        }
+    }
+    return false
+}
+
+// GetDetectedUnitsByCoalition returns the total count of detected units of a coalition
+func (mc *MessageCollection) GetDetectedUnitsByCoalition(coalition string) int {
     total := 0
     for _, msg := range mc.Messages {
-        if msg.Event == "unit_detected" {
-            // Parse count to int, default to 1 if parsing fails
-            count := 1
-            if msg.Count != "" {
-                if parsedCount, err := strconv.Atoi(msg.Count); err == nil {
-                    count = parsedCount
-                }
-            }
-            total += count
+        if msg.Event == "unit_detected" && msg.Coalition.Is(coalition) {
+            total += msg.Count
+        }
+    }
+    return total
+}
+
+// GetDetectedUnitsByCoalitionInZone returns the total count of detected units
+// of a coalition in the zone
+func (mc *MessageCollection) GetDetectedUnitsByCoalitionInZone(coalition, zone string) int {
+    total := 0
+    for _, msg := range mc.Messages {
+        if msg.Event == "unit_detected" && msg.Coalition.Is(coalition) && msg.InZone(zone) {
+            total += msg.Count
         }
     }
     return total
@@ -0,0 +1,55 @@
+// pkg/models/message_collecion_test.go
+package models
+
+import "testing"
+
+func TestCoalitionQueries(t *testing.T) {
+    mc := NewMessageCollection()
+    for _, m := range []*Message{
+        {Event: "unit_detected", Zone: "ALPHA", Coalition: CoalitionRed, Count: 3},
+        {Event: "unit_detected", Zone: "BRAVO", Coalition: CoalitionRed, Count: 2},
+        {Event: "unit_detected", Zone: "BRAVO", Coalition: CoalitionBlue, Count: 4},
+        {Event: "unit_destroyed", Zone: "BRAVO", Coalition: CoalitionBlue},
+        {Event: "unit_destroyed", Zone: "ALPHA"},
+    } {
+        mc.AddMessage(m)
+    }
+
+    tests := []struct {
+        name string
+        got  int
+        want int
+    }{
+        {`CountMessagesByCoalition("red")`, mc.CountMessagesByCoalition("red"), 2},
+        {`CountMessagesByCoalition("2")`, mc.CountMessagesByCoalition("2"), 2},
+        {`CountMessagesByCoalition("")`, mc.CountMessagesByCoalition(""), 0},
+        {`len(GetMessagesByCoalition("Blue"))`, len(mc.GetMessagesByCoalition("Blue")), 2},
+        {`CountMessagesByEventAndCoalition("unit_destroyed", "blue")`, mc.CountMessagesByEventAndCoalition("unit_destroyed", "blue"), 1},
+        {`CountMessagesByEventAndCoalition("unit_destroyed", "red")`, mc.CountMessagesByEventAndCoalition("unit_destroyed", "red"), 0},
+        {`GetDetectedUnitsByCoalition("RED")`, mc.GetDetectedUnitsByCoalition("RED"), 5},
+        {`GetDetectedUnitsByCoalition("purple")`, mc.GetDetectedUnitsByCoalition("purple"), 0},
+        {`GetDetectedUnitsByCoalitionInZone("red", "BRAVO")`, mc.GetDetectedUnitsByCoalitionInZone("red", "BRAVO"), 2},
+        {`GetDetectedUnitsByCoalitionInZone("blue", "ALPHA")`, mc.GetDetectedUnitsByCoalitionInZone("blue", "ALPHA"), 0},
+    }
+    for _, tt := range tests {
+        if tt.got != tt.want {
+            t.Errorf("%s = %d, want %d", tt.name, tt.got, tt.want)
+        }
+    }
+
+    zones := []struct {
+        name string
+        got  bool
+        want bool
+    }{
+        {`HasCoalitionInZone("blue", "BRAVO")`, mc.HasCoalitionInZone("blue", "BRAVO"), true},
+        {`HasCoalitionInZone("blue", "ALPHA")`, mc.HasCoalitionInZone("blue", "ALPHA"), false},
+        {`HasDetectionsOfCoalitionInZone("1", "ALPHA")`, mc.HasDetectionsOfCoalitionInZone("1", "ALPHA"), true},
+        {`HasDetectionsOfCoalitionInZone("blue", "ALPHA")`, mc.HasDetectionsOfCoalitionInZone("blue", "ALPHA"), false},
+    }
+    for _, tt := range zones {
+        if tt.got != tt.want {
+            t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+        }
+    }
+}
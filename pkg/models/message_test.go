@@ -0,0 +1,99 @@
+// pkg/models/message_test.go
+package models
+
+import "testing"
+
+func TestParseAlertLevel(t *testing.T) {
+    tests := []struct {
+        level string
+        want  AlertLevel
+        ok    bool
+    }{
+        {"green", AlertLevelGreen, true},
+        {"Yellow", AlertLevelYellow, true},
+        {" RED ", AlertLevelRed, true},
+        {"orange", "", false},
+        {"", "", false},
+    }
+    for _, tt := range tests {
+        got, err := ParseAlertLevel(tt.level)
+        if (err == nil) != tt.ok || got != tt.want {
+            t.Errorf("ParseAlertLevel(%q) = %q, %v, want %q", tt.level, got, err, tt.want)
+        }
+    }
+}
+
+func TestMessagePayloadFields(t *testing.T) {
+    message := NewMessage("weapon_fired")
+    message.Data = map[string]interface{}{
+        "weapon":   "AIM-120C",
+        "range":    "12.5",
+        "position": map[string]interface{}{"x": 1500.0, "y": 300.0},
+        "targets":  []interface{}{map[string]interface{}{"name": "Bandit-1", "locked": true}},
+        "guided":   "true",
+        "empty":    nil,
+    }
+
+    tests := []struct {
+        path   string
+        has    bool
+        str    string
+        number float64
+        flag   bool
+    }{
+        {"weapon", true, "AIM-120C", 0, false},
+        {"range", true, "12.5", 12.5, false},
+        {"position.x", true, "1500", 1500, false},
+        {"targets.0.name", true, "Bandit-1", 0, false},
+        {"targets.0.locked", true, "true", 0, true},
+        {"guided", true, "true", 0, true},
+        {"empty", true, "", 0, false},
+        {"targets.1.name", false, "", 0, false},
+        {"targets.x", false, "", 0, false},
+        {"position.x.y", false, "", 0, false},
+        {"missing", false, "", 0, false},
+    }
+    for _, tt := range tests {
+        if got := message.HasField(tt.path); got != tt.has {
+            t.Errorf("HasField(%q) = %v, want %v", tt.path, got, tt.has)
+        }
+        if got := message.GetString(tt.path); got != tt.str {
+            t.Errorf("GetString(%q) = %q, want %q", tt.path, got, tt.str)
+        }
+        if got := message.GetNumber(tt.path); got != tt.number {
+            t.Errorf("GetNumber(%q) = %v, want %v", tt.path, got, tt.number)
+        }
+        if got := message.GetBool(tt.path); got != tt.flag {
+            t.Errorf("GetBool(%q) = %v, want %v", tt.path, got, tt.flag)
+        }
+    }
+
+    if NewMessage("tick").GetString("anything") != "" {
+        t.Error("a message without payload has fields")
+    }
+}
+
+func TestParseCoalition(t *testing.T) {
+    tests := []struct {
+        coalition string
+        want      Coalition
+        ok        bool
+    }{
+        {"red", CoalitionRed, true},
+        {" Blue ", CoalitionBlue, true},
+        {"NEUTRAL", CoalitionNeutral, true},
+        {"neutrals", CoalitionNeutral, true},
+        {"0", CoalitionNeutral, true},
+        {"1", CoalitionRed, true},
+        {"2", CoalitionBlue, true},
+        {"3", "", false},
+        {"purple", "", false},
+        {"", "", false},
+    }
+    for _, tt := range tests {
+        got, err := ParseCoalition(tt.coalition)
+        if (err == nil) != tt.ok || got != tt.want {
+            t.Errorf("ParseCoalition(%q) = %q, %v, want %q", tt.coalition, got, err, tt.want)
+        }
+    }
+}
@@ -0,0 +1,146 @@
+// pkg/models/position.go
+package models
+
+import (
+    "math"
+)
+
+// Position is a point in DCS world coordinates, in metres.
+// X points north, Z east and Y is the altitude.
+type Position struct {
+    X float64 `json:"x"`
+    Y float64 `json:"y"`
+    Z float64 `json:"z"`
+}
+
+// DistanceTo returns the straight-line distance to another position
+func (p Position) DistanceTo(other Position) float64 {
+    dx, dy, dz := p.X-other.X, p.Y-other.Y, p.Z-other.Z
+    return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
+
+// GroundDistanceTo returns the distance to another position ignoring altitude
+func (p Position) GroundDistanceTo(other Position) float64 {
+    dx, dz := p.X-other.X, p.Z-other.Z
+    return math.Sqrt(dx*dx + dz*dz)
+}
+
+// DistanceToPoint returns the straight-line distance of the message's position
+// to the given point, or -1 if the event had no position
+func (m *Message) DistanceToPoint(x, y, z float64) float64 {
+    if !m.HasPosition {
+        return -1
+    }
+    return m.Position.DistanceTo(Position{X: x, Y: y, Z: z})
+}
+
+// DistanceTo returns the straight-line distance between the positions of two
+// messages, or -1 if either event had no position
+func (m *Message) DistanceTo(other *Message) float64 {
+    if other == nil || !m.HasPosition || !other.HasPosition {
+        return -1
+    }
+    return m.Position.DistanceTo(other.Position)
+}
+
+// unitPosition returns the position of the named unit from its latest
+// message that carried one
+func (mc *MessageCollection) unitPosition(unitName string) (Position, bool) {
+    for i := len(mc.Messages) - 1; i >= 0; i-- {
+        msg := mc.Messages[i]
+        if msg.UnitName == unitName && msg.HasPosition {
+            return msg.Position, true
+        }
+    }
+    return Position{}, false
+}
+
+// detectionsWithPosition returns the unit_detected messages that carry a position
+func (mc *MessageCollection) detectionsWithPosition() []*Message {
+    var result []*Message
+    for _, msg := range mc.Messages {
+        if msg.Event == "unit_detected" && msg.HasPosition {
+            result = append(result, msg)
+        }
+    }
+    return result
+}
+
+// DistanceBetween returns the distance in metres between two units, using the
+// latest position reported for each, or -1 if either has no known position
+func (mc *MessageCollection) DistanceBetween(unitA, unitB string) float64 {
+    a, okA := mc.unitPosition(unitA)
+    b, okB := mc.unitPosition(unitB)
+    if !okA || !okB {
+        return -1
+    }
+    return a.DistanceTo(b)
+}
+
+// CountDetectionsWithin returns the number of detections within radius metres of a point
+func (mc *MessageCollection) CountDetectionsWithin(x, y, z, radius float64) int {
+    point := Position{X: x, Y: y, Z: z}
+    count := 0
+    for _, msg := range mc.detectionsWithPosition() {
+        if msg.Position.DistanceTo(point) <= radius {
+            count++
+        }
+    }
+    return count
+}
+
+// CountDetectionsNearUnit returns the number of detections within radius metres
+// of the named unit, not counting the unit itself
+func (mc *MessageCollection) CountDetectionsNearUnit(unitName string, radius float64) int {
+    center, ok := mc.unitPosition(unitName)
+    if !ok {
+        return 0
+    }
+    count := 0
+    for _, msg := range mc.detectionsWithPosition() {
+        if msg.UnitName != unitName && msg.Position.DistanceTo(center) <= radius {
+            count++
+        }
+    }
+    return count
+}
+
+// NearestDetectedUnit returns the name of the detected unit closest to any
+// unit of the named group, or "" if there is none
+func (mc *MessageCollection) NearestDetectedUnit(groupName string) string {
+    nearest, _ := mc.nearestDetection(groupName)
+    if nearest == nil {
+        return ""
+    }
+    return nearest.UnitName
+}
+
+// DistanceToNearestDetection returns the distance in metres from the named
+// group to the closest detected unit, or -1 if there is none
+func (mc *MessageCollection) DistanceToNearestDetection(groupName string) float64 {
+    nearest, distance := mc.nearestDetection(groupName)
+    if nearest == nil {
+        return -1
+    }
+    return distance
+}
+
+// nearestDetection finds the detection closest to any positioned message of the group
+func (mc *MessageCollection) nearestDetection(groupName string) (*Message, float64) {
+    var nearest *Message
+    best := math.Inf(1)
+    for _, member := range mc.Messages {
+        if member.GroupName != groupName || !member.HasPosition {
+            continue
+        }
+        for _, detection := range mc.detectionsWithPosition() {
+            if detection.GroupName == groupName {
+                continue
+            }
+            if d := member.Position.DistanceTo(detection.Position); d < best {
+                nearest, best = detection, d
+            }
+        }
+    }
+    return nearest, best
+}
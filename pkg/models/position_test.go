@@ -0,0 +1,75 @@
+// pkg/models/position_test.go
+package models
+
+import "testing"
+
+// positioned returns a message of a unit at a position
+func positioned(event, unit, group string, x, y, z float64) *Message {
+    message := NewMessage(event)
+    message.UnitName = unit
+    message.GroupName = group
+    message.Position = Position{X: x, Y: y, Z: z}
+    message.HasPosition = true
+    return message
+}
+
+func TestPositionDistances(t *testing.T) {
+    origin := Position{}
+    tests := []struct {
+        name string
+        got  float64
+        want float64
+    }{
+        {"DistanceTo", origin.DistanceTo(Position{X: 3, Y: 12, Z: 4}), 13},
+        {"GroundDistanceTo ignores altitude", origin.GroundDistanceTo(Position{X: 3, Y: 12, Z: 4}), 5},
+        {"DistanceTo itself", Position{X: 7, Y: 7, Z: 7}.DistanceTo(Position{X: 7, Y: 7, Z: 7}), 0},
+        {"message DistanceToPoint", positioned("unit_detected", "a", "", 0, 0, 0).DistanceToPoint(6, 0, 8), 10},
+        {"DistanceToPoint without position", NewMessage("unit_detected").DistanceToPoint(6, 0, 8), -1},
+        {"message DistanceTo", positioned("unit_detected", "a", "", 0, 0, 0).DistanceTo(positioned("unit_detected", "b", "", 0, 0, 5)), 5},
+        {"DistanceTo a message without position", positioned("unit_detected", "a", "", 0, 0, 0).DistanceTo(NewMessage("unit_detected")), -1},
+        {"DistanceTo nil", positioned("unit_detected", "a", "", 0, 0, 0).DistanceTo(nil), -1},
+    }
+    for _, tt := range tests {
+        if tt.got != tt.want {
+            t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+        }
+    }
+}
+
+func TestCollectionProximity(t *testing.T) {
+    mc := NewMessageCollection()
+    mc.AddMessage(positioned("unit_spawned", "Viper-1", "Viper", 0, 0, 0))
+    mc.AddMessage(positioned("unit_detected", "SAM-1", "SAM", 300, 0, 400))
+    mc.AddMessage(positioned("unit_detected", "SAM-2", "SAM", 0, 0, 2000))
+    mc.AddMessage(positioned("unit_detected", "Viper-2", "Viper", 0, 0, -100))
+    mc.AddMessage(NewMessage("unit_detected"))
+    // The latest position of a unit counts
+    mc.AddMessage(positioned("unit_moved", "Viper-1", "Viper", 0, 0, 1000))
+
+    tests := []struct {
+        name string
+        got  float64
+        want float64
+    }{
+        {"DistanceBetween", mc.DistanceBetween("Viper-1", "SAM-2"), 1000},
+        {"DistanceBetween an unknown unit", mc.DistanceBetween("Viper-1", "Hawg-1"), -1},
+        {"CountDetectionsWithin", float64(mc.CountDetectionsWithin(0, 0, 0, 500)), 2},
+        {"CountDetectionsWithin nothing", float64(mc.CountDetectionsWithin(5000, 0, 5000, 10)), 0},
+        {"CountDetectionsNearUnit skips the unit", float64(mc.CountDetectionsNearUnit("SAM-2", 1000)), 0},
+        {"CountDetectionsNearUnit", float64(mc.CountDetectionsNearUnit("Viper-1", 1000)), 2},
+        {"CountDetectionsNearUnit of an unknown unit", float64(mc.CountDetectionsNearUnit("Hawg-1", 1000)), 0},
+        {"DistanceToNearestDetection skips the own group", mc.DistanceToNearestDetection("Viper"), 500},
+        {"DistanceToNearestDetection of an unknown group", mc.DistanceToNearestDetection("Hawg"), -1},
+    }
+    for _, tt := range tests {
+        if tt.got != tt.want {
+            t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+        }
+    }
+    if got := mc.NearestDetectedUnit("Viper"); got != "SAM-1" {
+        t.Errorf("NearestDetectedUnit = %q, want SAM-1", got)
+    }
+    if got := mc.NearestDetectedUnit("Hawg"); got != "" {
+        t.Errorf("NearestDetectedUnit of an unknown group = %q, want none", got)
+    }
+}
@@ -0,0 +1,178 @@
+// pkg/models/query.go
+package models
+
+import (
+    "sort"
+)
+
+// fieldValues returns the values of a message field used by collection queries.
+// event, zone, unit_type, unit_name, group_name, coalition, level and
+// mission_id select the dedicated fields; "zone" yields every zone the message
+// is in. Any other name is looked up as a payload path. A missing field
+// yields no values.
+func (m *Message) fieldValues(field string) []string {
+    var value string
+    switch field {
+    case "event":
+        value = m.Event
+    case "zone":
+        var zones []string
+        if m.Zone != "" {
+            zones = append(zones, m.Zone)
+        }
+        for _, zone := range m.Zones {
+            if zone != m.Zone {
+                zones = append(zones, zone)
+            }
+        }
+        return zones
+    case "unit_type":
+        value = m.UnitType
+    case "unit_name":
+        value = m.UnitName
+    case "group_name":
+        value = m.GroupName
+    case "coalition":
+        value = string(m.Coalition)
+    case "level":
+        value = string(m.Level)
+    case "mission_id":
+        value = m.MissionID
+    default:
+        if !m.HasField(field) {
+            return nil
+        }
+        value = m.GetString(field)
+    }
+    if value == "" {
+        return nil
+    }
+    return []string{value}
+}
+
+// Where returns the messages whose field has the value, see fieldValues for
+// the field names. Queries chain, e.g.
+// Messages.Where("event", "unit_detected").Where("coalition", "red").SumCount()
+func (mc *MessageCollection) Where(field, value string) *MessageCollection {
+    result := NewMessageCollection()
+    for _, msg := range mc.Messages {
+        for _, v := range msg.fieldValues(field) {
+            if v == value {
+                result.AddMessage(msg)
+                break
+            }
+        }
+    }
+    return result
+}
+
+// WithEvent returns the messages of an event type
+func (mc *MessageCollection) WithEvent(eventType string) *MessageCollection {
+    return mc.Where("event", eventType)
+}
+
+// InZone returns the messages in a zone
+func (mc *MessageCollection) InZone(zone string) *MessageCollection {
+    return mc.Where("zone", zone)
+}
+
+// WithUnitType returns the messages about units of a type
+func (mc *MessageCollection) WithUnitType(unitType string) *MessageCollection {
+    return mc.Where("unit_type", unitType)
+}
+
+// WithCoalition returns the messages about units of a coalition, named as for Coalition.Is
+func (mc *MessageCollection) WithCoalition(coalition string) *MessageCollection {
+    if parsed, err := ParseCoalition(coalition); err == nil {
+        coalition = string(parsed)
+    }
+    return mc.Where("coalition", coalition)
+}
+
+// Count returns the number of messages
+func (mc *MessageCollection) Count() int {
+    return len(mc.Messages)
+}
+
+// SumCount returns the total Count of the messages, e.g. the number of units detected
+func (mc *MessageCollection) SumCount() int {
+    total := 0
+    for _, msg := range mc.Messages {
+        total += msg.Count
+    }
+    return total
+}
+
+// Sum returns the total of a numeric payload field over the messages
+func (mc *MessageCollection) Sum(path string) float64 {
+    total := 0.0
+    for _, msg := range mc.Messages {
+        total += msg.GetNumber(path)
+    }
+    return total
+}
+
+// CountBy returns the number of messages per value of a field, e.g.
+// Messages.WithEvent("unit_detected").CountBy("zone")["ALPHA"]. A message in
+// several zones counts for each of them.
+func (mc *MessageCollection) CountBy(field string) map[string]int {
+    counts := make(map[string]int)
+    for _, msg := range mc.Messages {
+        for _, v := range msg.fieldValues(field) {
+            counts[v]++
+        }
+    }
+    return counts
+}
+
+// Distinct returns the distinct values of a field, sorted
+func (mc *MessageCollection) Distinct(field string) []string {
+    counts := mc.CountBy(field)
+    values := make([]string, 0, len(counts))
+    for v := range counts {
+        values = append(values, v)
+    }
+    sort.Strings(values)
+    return values
+}
+
+// CountDistinct returns the number of distinct values of a field, e.g. the
+// number of zones with detections: Messages.WithEvent("unit_detected").CountDistinct("zone")
+func (mc *MessageCollection) CountDistinct(field string) int {
+    return len(mc.CountBy(field))
+}
+
+// MaxCountBy returns the size of the largest group of messages sharing a
+// value of the field, e.g. the most detections in any one zone
+func (mc *MessageCollection) MaxCountBy(field string) int {
+    max := 0
+    for _, count := range mc.CountBy(field) {
+        if count > max {
+            max = count
+        }
+    }
+    return max
+}
+
+// CountGroupsWithAtLeast returns the number of values of the field shared by
+// at least min messages, e.g. the number of zones with 3 or more detections
+func (mc *MessageCollection) CountGroupsWithAtLeast(field string, min int64) int {
+    groups := 0
+    for _, count := range mc.CountBy(field) {
+        if int64(count) >= min {
+            groups++
+        }
+    }
+    return groups
+}
+
+// HasAllZones reports whether there is a message in each of the zones
+func (mc *MessageCollection) HasAllZones(zones ...string) bool {
+    counts := mc.CountBy("zone")
+    for _, zone := range zones {
+        if counts[zone] == 0 {
+            return false
+        }
+    }
+    return true
+}
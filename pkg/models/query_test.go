@@ -0,0 +1,65 @@
+// pkg/models/query_test.go
+package models
+
+import (
+    "fmt"
+    "testing"
+)
+
+func queryMessages() *MessageCollection {
+    mc := NewMessageCollection()
+    for _, m := range []*Message{
+        {Event: "unit_detected", Zone: "ALPHA", Zones: []string{"ALPHA", "NORTH"}, UnitType: "MiG-29", Coalition: CoalitionRed, Count: 2, Data: map[string]interface{}{"altitude": 3000.0}},
+        {Event: "unit_detected", Zone: "ALPHA", UnitType: "Su-27", Coalition: CoalitionRed, Count: 1, Data: map[string]interface{}{"altitude": "1500"}},
+        {Event: "unit_detected", Zone: "BRAVO", UnitType: "MiG-29", Coalition: CoalitionBlue, Count: 4},
+        {Event: "unit_destroyed", Zone: "BRAVO", UnitType: "SA-6", Coalition: CoalitionRed},
+        {Event: "alert_level_change", Zones: []string{"NORTH"}, Level: AlertLevelRed},
+    } {
+        mc.AddMessage(m)
+    }
+    return mc
+}
+
+func TestMessageCollectionQueries(t *testing.T) {
+    mc := queryMessages()
+    detections := mc.WithEvent("unit_detected")
+
+    tests := []struct {
+        name string
+        got  interface{}
+        want interface{}
+    }{
+        {"Count", mc.Count(), 5},
+        {"WithEvent", detections.Count(), 3},
+        {"SumCount", detections.SumCount(), 7},
+        {"chained Where", mc.Where("event", "unit_detected").Where("coalition", "red").SumCount(), 3},
+        {"Where on a payload field", mc.Where("altitude", "1500").Count(), 1},
+        {"Where on a missing field", mc.Where("weapon", "").Count(), 0},
+        {"InZone by name", mc.InZone("ALPHA").Count(), 2},
+        {"InZone by position", mc.InZone("NORTH").Count(), 2},
+        {"WithUnitType", mc.WithUnitType("MiG-29").SumCount(), 6},
+        {"WithCoalition by number", mc.WithCoalition("2").Count(), 1},
+        {"WithCoalition in any case", mc.WithCoalition("RED").Count(), 3},
+        {"Sum of a payload field", detections.Sum("altitude"), 4500.0},
+        {"CountBy zone", fmt.Sprint(detections.CountBy("zone")), "map[ALPHA:2 BRAVO:1 NORTH:1]"},
+        {"CountBy a field without values", len(mc.CountBy("group_name")), 0},
+        {"Distinct", fmt.Sprint(mc.Distinct("unit_type")), "[MiG-29 SA-6 Su-27]"},
+        {"CountDistinct", detections.CountDistinct("zone"), 3},
+        {"MaxCountBy", detections.MaxCountBy("zone"), 2},
+        {"MaxCountBy of nothing", mc.WithEvent("mission_end").MaxCountBy("zone"), 0},
+        {"CountGroupsWithAtLeast", mc.CountGroupsWithAtLeast("zone", 2), 3},
+        {"CountGroupsWithAtLeast 3", mc.CountGroupsWithAtLeast("zone", 3), 0},
+        {"HasAllZones", mc.HasAllZones("ALPHA", "BRAVO", "NORTH"), true},
+        {"HasAllZones missing one", detections.HasAllZones("ALPHA", "CHARLIE"), false},
+        {"HasAllZones of none", mc.HasAllZones(), true},
+    }
+    for _, tt := range tests {
+        if tt.got != tt.want {
+            t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+        }
+    }
+
+    if mc.Count() != 5 {
+        t.Error("queries modified the collection")
+    }
+}
@@ -0,0 +1,229 @@
+// pkg/models/world.go
+package models
+
+import (
+    "sync"
+)
+
+// DefaultMissionID is used for events that do not name a mission
+const DefaultMissionID = "default"
+
+// UnitState is what is known about a unit from the events seen so far
+type UnitState struct {
+    Name        string
+    Type        string
+    Group       string
+    Coalition   Coalition
+    Zone        string
+    Alive       bool
+    LastSeen    int64 // Timestamp of the latest event about the unit
+    DestroyedAt int64
+}
+
+// ZoneState is what is known about a zone from the events seen so far
+type ZoneState struct {
+    Name       string
+    AlertLevel AlertLevel
+    Owner      Coalition
+}
+
+// WorldState accumulates the state of one mission across evaluations. It is
+// updated from every incoming event before the rules run and exposed to rules
+// as the World fact. All state is behind query methods so concurrent
+// evaluations of the same mission can share it.
+type WorldState struct {
+    MissionID string
+
+    mu     sync.RWMutex
+    units  map[string]*UnitState
+    groups map[string]Coalition
+    zones  map[string]*ZoneState
+    events int
+}
+
+// NewWorldState creates an empty world state for a mission
+func NewWorldState(missionID string) *WorldState {
+    return &WorldState{
+        MissionID: missionID,
+        units:     make(map[string]*UnitState),
+        groups:    make(map[string]Coalition),
+        zones:     make(map[string]*ZoneState),
+    }
+}
+
+// Apply updates the world state from a message
+func (ws *WorldState) Apply(m *Message) {
+    ws.mu.Lock()
+    defer ws.mu.Unlock()
+
+    ws.events++
+
+    if m.GroupName != "" {
+        if _, seen := ws.groups[m.GroupName]; !seen || m.Coalition != "" {
+            ws.groups[m.GroupName] = m.Coalition
+        }
+    }
+
+    if m.UnitName != "" {
+        unit, ok := ws.units[m.UnitName]
+        if !ok {
+            unit = &UnitState{Name: m.UnitName, Alive: true}
+            ws.units[m.UnitName] = unit
+        }
+        if m.UnitType != "" {
+            unit.Type = m.UnitType
+        }
+        if m.GroupName != "" {
+            unit.Group = m.GroupName
+        }
+        if m.Coalition != "" {
+            unit.Coalition = m.Coalition
+        }
+        if m.Zone != "" {
+            unit.Zone = m.Zone
+        }
+        unit.LastSeen = m.Timestamp
+        if m.Event == "unit_destroyed" {
+            unit.Alive = false
+            unit.DestroyedAt = m.Timestamp
+        }
+    }
+
+    if m.Zone == "" {
+        return
+    }
+    switch m.Event {
+    case "alert_level_change":
+        ws.zone(m.Zone).AlertLevel = m.Level
+    case "zone_captured":
+        ws.zone(m.Zone).Owner = m.Coalition
+    }
+}
+
+// zone returns the state of a zone, creating it on first use. Callers hold the write lock.
+func (ws *WorldState) zone(name string) *ZoneState {
+    zone, ok := ws.zones[name]
+    if !ok {
+        zone = &ZoneState{Name: name}
+        ws.zones[name] = zone
+    }
+    return zone
+}
+
+// EventCount returns the number of events applied to the world state
+func (ws *WorldState) EventCount() int {
+    ws.mu.RLock()
+    defer ws.mu.RUnlock()
+    return ws.events
+}
+
+// IsUnitKnown reports whether any event has mentioned the unit
+func (ws *WorldState) IsUnitKnown(unitName string) bool {
+    ws.mu.RLock()
+    defer ws.mu.RUnlock()
+    _, ok := ws.units[unitName]
+    return ok
+}
+
+// IsUnitAlive reports whether the unit is known and has not been destroyed
+func (ws *WorldState) IsUnitAlive(unitName string) bool {
+    ws.mu.RLock()
+    defer ws.mu.RUnlock()
+    unit, ok := ws.units[unitName]
+    return ok && unit.Alive
+}
+
+// countUnits counts the units matching all non-empty filters. The coalition
+// is named as for Coalition.Is.
+func (ws *WorldState) countUnits(alive bool, coalition, unitType, zone string) int {
+    ws.mu.RLock()
+    defer ws.mu.RUnlock()
+    count := 0
+    for _, unit := range ws.units {
+        if unit.Alive != alive ||
+            (coalition != "" && !unit.Coalition.Is(coalition)) ||
+            (unitType != "" && unit.Type != unitType) ||
+            (zone != "" && unit.Zone != zone) {
+            continue
+        }
+        count++
+    }
+    return count
+}
+
+// CountAlive returns the number of known living units of a coalition
+func (ws *WorldState) CountAlive(coalition string) int {
+    return ws.countUnits(true, coalition, "", "")
+}
+
+// CountAliveInZone returns the number of known living units of a coalition last seen in the zone
+func (ws *WorldState) CountAliveInZone(coalition, zone string) int {
+    return ws.countUnits(true, coalition, "", zone)
+}
+
+// CountDestroyed returns the number of destroyed units of a coalition
+func (ws *WorldState) CountDestroyed(coalition string) int {
+    return ws.countUnits(false, coalition, "", "")
+}
+
+// CountDestroyedInZone returns the number of units of any coalition destroyed in the zone
+func (ws *WorldState) CountDestroyedInZone(zone string) int {
+    return ws.countUnits(false, "", "", zone)
+}
+
+// CountLossesInZone returns the number of units of a coalition destroyed in the zone
+func (ws *WorldState) CountLossesInZone(coalition, zone string) int {
+    return ws.countUnits(false, coalition, "", zone)
+}
+
+// CountDestroyedByTypeInZone returns the number of units of a type destroyed
+// in the zone, e.g. ("SA-6", "BRAVO")
+func (ws *WorldState) CountDestroyedByTypeInZone(unitType, zone string) int {
+    return ws.countUnits(false, "", unitType, zone)
+}
+
+// HasSeenGroup reports whether any event has mentioned the group
+func (ws *WorldState) HasSeenGroup(groupName string) bool {
+    ws.mu.RLock()
+    defer ws.mu.RUnlock()
+    _, ok := ws.groups[groupName]
+    return ok
+}
+
+// CountGroupsSeen returns the number of groups of a coalition seen so far
+func (ws *WorldState) CountGroupsSeen(coalition string) int {
+    ws.mu.RLock()
+    defer ws.mu.RUnlock()
+    count := 0
+    for _, groupCoalition := range ws.groups {
+        if groupCoalition.Is(coalition) {
+            count++
+        }
+    }
+    return count
+}
+
+// GetZoneAlertLevel returns the latest alert level reported for the zone, or ""
+func (ws *WorldState) GetZoneAlertLevel(zone string) string {
+    ws.mu.RLock()
+    defer ws.mu.RUnlock()
+    if state, ok := ws.zones[zone]; ok {
+        return string(state.AlertLevel)
+    }
+    return ""
+}
+
+// GetZoneOwner returns the coalition that last captured the zone, or ""
+func (ws *WorldState) GetZoneOwner(zone string) string {
+    ws.mu.RLock()
+    defer ws.mu.RUnlock()
+    if state, ok := ws.zones[zone]; ok {
+        return string(state.Owner)
+    }
+    return ""
+}
+
+// IsZoneOwnedBy reports whether the coalition, named as for Coalition.Is, owns the zone
+func (ws *WorldState) IsZoneOwnedBy(zone, coalition string) bool {
+    return Coalition(ws.GetZoneOwner(zone)).Is(coalition)
+}
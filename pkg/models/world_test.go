@@ -0,0 +1,72 @@
+// pkg/models/world_test.go
+package models
+
+import "testing"
+
+func TestWorldStateCoalitionNames(t *testing.T) {
+    ws := NewWorldState(DefaultMissionID)
+    for _, u := range []struct {
+        name, group string
+        coalition   Coalition
+        event       string
+    }{
+        {"Viper-1", "Viper", "BLUE", "unit_detected"},
+        {"Viper-2", "Viper", "2", "unit_detected"},
+        {"Hawg-1", "Hawg", CoalitionBlue, "unit_destroyed"},
+        {"SAM-1", "SAM", CoalitionRed, "unit_detected"},
+    } {
+        ws.Apply(&Message{Event: u.event, UnitName: u.name, GroupName: u.group, Coalition: u.coalition, Zone: "BRAVO"})
+    }
+    ws.Apply(&Message{Event: "zone_captured", Coalition: "RED", Zone: "ALPHA"})
+
+    tests := []struct {
+        name string
+        got  int
+        want int
+    }{
+        {`CountAlive("blue")`, ws.CountAlive("blue"), 2},
+        {`CountAlive("Blue")`, ws.CountAlive("Blue"), 2},
+        {`CountAlive("2")`, ws.CountAlive("2"), 2},
+        {`CountAlive("red")`, ws.CountAlive("red"), 1},
+        {`CountAlive("purple")`, ws.CountAlive("purple"), 0},
+        {`CountAlive("")`, ws.CountAlive(""), 3},
+        {`CountAliveInZone("BLUE", "BRAVO")`, ws.CountAliveInZone("BLUE", "BRAVO"), 2},
+        {`CountLossesInZone("blue", "BRAVO")`, ws.CountLossesInZone("blue", "BRAVO"), 1},
+        {`CountGroupsSeen("blue")`, ws.CountGroupsSeen("blue"), 2},
+        {`CountGroupsSeen("1")`, ws.CountGroupsSeen("1"), 1},
+    }
+    if !ws.IsZoneOwnedBy("ALPHA", "red") || ws.IsZoneOwnedBy("ALPHA", "blue") || ws.IsZoneOwnedBy("BRAVO", "") {
+        t.Error("IsZoneOwnedBy does not match the owner of ALPHA by coalition name")
+    }
+    for _, tt := range tests {
+        if tt.got != tt.want {
+            t.Errorf("%s = %d, want %d", tt.name, tt.got, tt.want)
+        }
+    }
+}
+
+func TestCoalitionIs(t *testing.T) {
+    tests := []struct {
+        coalition Coalition
+        name      string
+        want      bool
+    }{
+        {CoalitionBlue, "blue", true},
+        {CoalitionBlue, " BLUE ", true},
+        {CoalitionBlue, "2", true},
+        {CoalitionRed, "1", true},
+        {CoalitionNeutral, "neutrals", true},
+        {CoalitionNeutral, "0", true},
+        {"BLUE", "blue", true},
+        {"2", "Blue", true},
+        {CoalitionBlue, "red", false},
+        {CoalitionBlue, "purple", false},
+        {"", "", false},
+        {"purple", "purple", false},
+    }
+    for _, tt := range tests {
+        if got := tt.coalition.Is(tt.name); got != tt.want {
+            t.Errorf("Coalition(%q).Is(%q) = %v, want %v", tt.coalition, tt.name, got, tt.want)
+        }
+    }
+}
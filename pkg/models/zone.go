@@ -0,0 +1,102 @@
+// pkg/models/zone.go
+package models
+
+// Zone is a named area of the map. It is either a circle around Center or a
+// polygon, both in DCS x/z coordinates, optionally limited to an altitude band.
+type Zone struct {
+    Name        string
+    Center      *Position  // Set for circular zones
+    Radius      float64    // Radius of a circular zone in metres
+    Polygon     []Position // Vertices of a polygonal zone; only X and Z are used
+    MinAltitude *float64   // Optional lower bound of Y
+    MaxAltitude *float64   // Optional upper bound of Y
+}
+
+// Contains reports whether the position lies inside the zone
+func (z *Zone) Contains(p Position) bool {
+    if z.MinAltitude != nil && p.Y < *z.MinAltitude {
+        return false
+    }
+    if z.MaxAltitude != nil && p.Y > *z.MaxAltitude {
+        return false
+    }
+
+    if z.Center != nil {
+        return p.GroundDistanceTo(*z.Center) <= z.Radius
+    }
+    return polygonContains(z.Polygon, p)
+}
+
+// polygonContains tests a point against a polygon in the x/z plane by ray casting
+func polygonContains(polygon []Position, p Position) bool {
+    inside := false
+    for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+        a, b := polygon[i], polygon[j]
+        if (a.Z > p.Z) != (b.Z > p.Z) && p.X < (b.X-a.X)*(p.Z-a.Z)/(b.Z-a.Z)+a.X {
+            inside = !inside
+        }
+    }
+    return inside
+}
+
+// ZoneRegistry holds the configured zones in their configured order
+type ZoneRegistry struct {
+    zones []*Zone
+}
+
+// NewZoneRegistry creates a registry from the given zones
+func NewZoneRegistry(zones []*Zone) *ZoneRegistry {
+    return &ZoneRegistry{zones: zones}
+}
+
+// ZonesAt returns the names of all zones containing the position, in configured order
+func (zr *ZoneRegistry) ZonesAt(p Position) []string {
+    var names []string
+    for _, zone := range zr.zones {
+        if zone.Contains(p) {
+            names = append(names, zone.Name)
+        }
+    }
+    return names
+}
+
+// Len returns the number of zones in the registry
+func (zr *ZoneRegistry) Len() int {
+    return len(zr.zones)
+}
+
+// ResolveZones records the zones containing the message's position. If the
+// event did not name a zone, the first containing zone becomes Message.Zone.
+func (zr *ZoneRegistry) ResolveZones(m *Message) {
+    if !m.HasPosition || len(zr.zones) == 0 {
+        return
+    }
+    m.Zones = zr.ZonesAt(m.Position)
+    if m.Zone == "" && len(m.Zones) > 0 {
+        m.Zone = m.Zones[0]
+    }
+}
+
+// InZone reports whether the message was sent for the named zone or its
+// position lies inside it
+func (m *Message) InZone(name string) bool {
+    if m.Zone == name {
+        return true
+    }
+    for _, zone := range m.Zones {
+        if zone == name {
+            return true
+        }
+    }
+    return false
+}
+
+// InAnyZone reports whether the message is in at least one of the named zones
+func (m *Message) InAnyZone(names ...string) bool {
+    for _, name := range names {
+        if m.InZone(name) {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,81 @@
+// pkg/models/zone_test.go
+package models
+
+import (
+    "reflect"
+    "testing"
+)
+
+func testZones() *ZoneRegistry {
+    low, high := 0.0, 1000.0
+    return NewZoneRegistry([]*Zone{
+        {Name: "ALPHA", Center: &Position{X: 0, Z: 0}, Radius: 1000},
+        {Name: "BRAVO", Polygon: []Position{{X: 500, Z: 500}, {X: 2500, Z: 500}, {X: 2500, Z: 2500}, {X: 500, Z: 2500}}},
+        {Name: "LOW", Center: &Position{X: 0, Z: 0}, Radius: 5000, MinAltitude: &low, MaxAltitude: &high},
+    })
+}
+
+func TestZonesAt(t *testing.T) {
+    zones := testZones()
+    tests := []struct {
+        name     string
+        position Position
+        want     []string
+    }{
+        {"circle center", Position{X: 0, Y: 100, Z: 0}, []string{"ALPHA", "LOW"}},
+        {"circle edge", Position{X: 600, Y: 100, Z: 800}, []string{"ALPHA", "BRAVO", "LOW"}},
+        {"polygon only", Position{X: 2000, Y: 100, Z: 2000}, []string{"BRAVO", "LOW"}},
+        {"above the altitude band", Position{X: 2000, Y: 3000, Z: 2000}, []string{"BRAVO"}},
+        {"below the altitude band", Position{X: 0, Y: -10, Z: 0}, []string{"ALPHA"}},
+        {"outside every zone", Position{X: -6000, Y: 100, Z: 0}, nil},
+        {"left of the polygon", Position{X: 400, Y: 3000, Z: 1000}, nil},
+    }
+    for _, tt := range tests {
+        if got := zones.ZonesAt(tt.position); !reflect.DeepEqual(got, tt.want) {
+            t.Errorf("%s: ZonesAt(%+v) = %v, want %v", tt.name, tt.position, got, tt.want)
+        }
+    }
+}
+
+func TestResolveZones(t *testing.T) {
+    zones := testZones()
+    tests := []struct {
+        name  string
+        zone  string
+        pos   *Position
+        want  string   // Message.Zone after resolution
+        zones []string // Message.Zones after resolution
+    }{
+        {"first containing zone", "", &Position{X: 2000, Y: 100, Z: 2000}, "BRAVO", []string{"BRAVO", "LOW"}},
+        {"named zone is kept", "CHARLIE", &Position{X: 0, Y: 100, Z: 0}, "CHARLIE", []string{"ALPHA", "LOW"}},
+        {"outside every zone", "", &Position{X: -6000, Y: 100, Z: 0}, "", nil},
+        {"no position", "CHARLIE", nil, "CHARLIE", nil},
+    }
+    for _, tt := range tests {
+        message := NewMessage("unit_detected")
+        message.Zone = tt.zone
+        if tt.pos != nil {
+            message.Position, message.HasPosition = *tt.pos, true
+        }
+        zones.ResolveZones(message)
+        if message.Zone != tt.want || !reflect.DeepEqual(message.Zones, tt.zones) {
+            t.Errorf("%s: zone %q in %v, want %q in %v", tt.name, message.Zone, message.Zones, tt.want, tt.zones)
+        }
+        for _, zone := range tt.zones {
+            if !message.InZone(zone) {
+                t.Errorf("%s: InZone(%q) = false", tt.name, zone)
+            }
+        }
+    }
+
+    message := NewMessage("unit_detected")
+    message.Position, message.HasPosition = Position{X: 0, Y: 100, Z: 0}, true
+    NewZoneRegistry(nil).ResolveZones(message)
+    if message.Zone != "" || message.Zones != nil {
+        t.Errorf("an empty registry resolved zone %q in %v", message.Zone, message.Zones)
+    }
+    zones.ResolveZones(message)
+    if !message.InAnyZone("BRAVO", "LOW") || message.InAnyZone("BRAVO", "CHARLIE") {
+        t.Errorf("InAnyZone does not match zones %v", message.Zones)
+    }
+}